@@ -49,6 +49,10 @@
 //   - Splitter: bufio.SplitFunc for tokenizing modem output
 //   - Classify: Response type classification for proper handling
 //   - ResponseType: Enum for different kinds of modem responses
+//   - SplitFields, Params and the Parse functions: Parsers for the
+//     parameters of common responses
+//   - Quote and the command builders (CMGS, CPIN, ...): Commands with
+//     properly quoted and escaped parameters
 package at
 
 const (
@@ -56,6 +60,7 @@ const (
 	CRLF   = "\r\n"
 	Prompt = "> "
 	CtrlZ  = "\x1A"
+	Esc    = "\x1B"
 
 	// Response Codes
 	OK         = "OK"
@@ -66,18 +71,58 @@ const (
 	NoAnswer   = "NO ANSWER"
 	CmeError   = "+CME ERROR:"
 	CmsError   = "+CMS ERROR:"
+	SimStatus  = "+CPIN:"
 	SimReady   = "+CPIN: READY"
 	SimPin     = "+CPIN: SIM PIN"
+	SimPuk     = "+CPIN: SIM PUK"
+	PinCounter = "+QPINC:"
+	PinStatus  = "^CPIN:"
+	RegStatus  = "+CREG:"
+	Charset    = "+CSCS:"
+	MsgWritten = "+CMGW:"
+	MsgFormat  = "+CMGF:"
+	NewMsgInd  = "+CNMI:"
+	MsgRead    = "+CMGR:"
+	Operator   = "+COPS:"
+	DataCount  = "+QGDCNT:"
+	DataFlow   = "^DSFLOWQRY:"
 
 	// Commands
 	CmdAt            = "AT"
 	CmdEchoOff       = "ATE0"
+	CmdResetProfile  = "ATZ"
+	CmdFactoryReset  = "AT&F"
+	CmdSaveProfile   = "AT&W"
 	CmdSetTextMode   = "AT+CMGF=1"
+	CmdSetPDUMode    = "AT+CMGF=0"
 	CmdVerboseErrors = "AT+CMEE=2"
 	CmdSimStatus     = "AT+CPIN?"
+	CmdPinCounter    = `AT+QPINC="SC"`
+	CmdPinStatus     = "AT^CPIN?"
+	CmdRegStatus     = "AT+CREG?"
+	CmdCharset       = "AT+CSCS?"
+	CmdMsgFormat     = "AT+CMGF?"
+	CmdNewMsgInd     = "AT+CNMI?"
+	CmdNewMsgStore   = "AT+CNMI=2,1,0,0,0"
+	CmdNewMsgDirect  = "AT+CNMI=2,2,0,0,0"
+	CmdMoreMessages  = "AT+CMMS=1"
+	CmdDataCount     = "AT+QGDCNT?"
+	CmdDataFlow      = "AT^DSFLOWQRY"
+	CmdSignal        = "AT+CSQ"
+	CmdOperator      = "AT+COPS?"
+	CmdManufacturer  = "AT+CGMI"
+	CmdModel         = "AT+CGMM"
+	CmdRevision      = "AT+CGMR"
+	CmdIMEI          = "AT+CGSN"
+
+	// Character sets (AT+CSCS)
+	CharsetGSM  = "GSM"
+	CharsetIRA  = "IRA"
+	CharsetUCS2 = "UCS2"
 
 	// URCs (Unsolicited Result Codes)
 	UrcNewMsg         = "+CMTI:"
+	UrcNewMsgDirect   = "+CMT:"
 	UrcMessageReport  = "+CDSI:"
 	UrcSignalStrength = "+CSQ:"
 	UrcCall           = "RING"
@@ -105,6 +150,9 @@ const (
 	// from the modem that are not direct responses to AT commands. These can
 	// arrive at any time and should be processed separately from command flows.
 	//
+	// Some URCs are followed by a payload line that belongs to them, see
+	// HasPayload.
+	//
 	// Examples: "+CMTI: \"SM\",1" (new SMS), "RING" (incoming call)
 	TypeURC
 
@@ -0,0 +1,58 @@
+package at
+
+import "strings"
+
+// Classifier classifies the lines a modem outputs, see Classify. Modems
+// with vendor specific URCs or result codes need a Classifier that knows
+// them, e.g. a PrefixClassifier.
+type Classifier interface {
+	Classify(line string) ResponseType
+}
+
+// ClassifierFunc adapts a function to the Classifier interface.
+type ClassifierFunc func(line string) ResponseType
+
+// Classify returns f(line).
+func (f ClassifierFunc) Classify(line string) ResponseType {
+	return f(line)
+}
+
+// DefaultClassifier classifies lines with Classify.
+var DefaultClassifier Classifier = ClassifierFunc(Classify)
+
+// PrefixClassifier extends another Classifier with line prefixes of a
+// known type, such as the vendor URC "^RSSI:" or the final result
+// "+CME ERROR" variant of a particular modem.
+//
+//	classifier := at.PrefixClassifier{
+//		Prefixes: map[string]at.ResponseType{
+//			"^RSSI:": at.TypeURC,
+//			"+QIND:": at.TypeURC,
+//		},
+//	}
+type PrefixClassifier struct {
+	// Prefixes maps line prefixes to their type. The longest matching
+	// prefix wins.
+	Prefixes map[string]ResponseType
+	// Base classifies the lines matching none of the prefixes,
+	// DefaultClassifier if nil
+	Base Classifier
+}
+
+// Classify returns the type of the longest prefix of line in Prefixes, or
+// defers to Base.
+func (c PrefixClassifier) Classify(line string) ResponseType {
+	longest, typ := -1, TypeData
+	for prefix, t := range c.Prefixes {
+		if len(prefix) > longest && strings.HasPrefix(line, prefix) {
+			longest, typ = len(prefix), t
+		}
+	}
+	if longest >= 0 {
+		return typ
+	}
+	if c.Base == nil {
+		return Classify(line)
+	}
+	return c.Base.Classify(line)
+}
@@ -0,0 +1,34 @@
+package at_test
+
+import (
+	"testing"
+
+	"i4.energy/across/smsgw/at"
+)
+
+func TestPrefixClassifier(t *testing.T) {
+	classifier := at.PrefixClassifier{
+		Prefixes: map[string]at.ResponseType{
+			"^":             at.TypeURC,
+			"^SYSINFO:":     at.TypeData,
+			"COMMAND NOT S": at.TypeFinal,
+		},
+	}
+
+	tests := []struct {
+		line string
+		want at.ResponseType
+	}{
+		{line: "^RSSI: 17", want: at.TypeURC},
+		{line: "^SYSINFO: 2,3,0,5,1", want: at.TypeData},
+		{line: "COMMAND NOT SUPPORT", want: at.TypeFinal},
+		{line: "OK", want: at.TypeFinal},
+		{line: "+CMTI: \"SM\",1", want: at.TypeURC},
+		{line: "+CSQ: 17,99", want: at.TypeData},
+	}
+	for _, tt := range tests {
+		if got := classifier.Classify(tt.line); got != tt.want {
+			t.Errorf("%q: expected type %d, got %d", tt.line, tt.want, got)
+		}
+	}
+}
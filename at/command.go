@@ -0,0 +1,81 @@
+package at
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Quote returns s as a quoted string parameter. Characters that would end
+// the parameter or the command line, the quote itself, the backslash and
+// control characters, are escaped as a backslash followed by two hex
+// digits, as 3GPP TS 27.007 defines for string parameters.
+//
+//	Quote(`say "hi"`) // `"say \22hi\22"`
+func Quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' || c < 0x20 || c == 0x7F {
+			const hex = "0123456789ABCDEF"
+			b.WriteByte('\\')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// CMGS returns the command sending a message to recipient in text mode.
+// The message text is entered after the prompt.
+func CMGS(recipient string) string {
+	return "AT+CMGS=" + Quote(recipient)
+}
+
+// CMGSPDU returns the command sending a message in PDU mode, taking the
+// length of the TPDU in octets without the SMSC address.
+func CMGSPDU(length int) string {
+	return "AT+CMGS=" + strconv.Itoa(length)
+}
+
+// CMGW returns the command writing a message to recipient to storage in
+// text mode. The message text is entered after the prompt.
+func CMGW(recipient string) string {
+	return "AT+CMGW=" + Quote(recipient)
+}
+
+// CMGWPDU returns the command writing a message to storage in PDU mode,
+// taking the length of the TPDU in octets without the SMSC address.
+func CMGWPDU(length int) string {
+	return "AT+CMGW=" + strconv.Itoa(length)
+}
+
+// CMSS returns the command sending the stored message at index.
+func CMSS(index int) string {
+	return "AT+CMSS=" + strconv.Itoa(index)
+}
+
+// CMGR returns the command reading the stored message at index.
+func CMGR(index int) string {
+	return "AT+CMGR=" + strconv.Itoa(index)
+}
+
+// CMGD returns the command deleting the stored message at index.
+func CMGD(index int) string {
+	return "AT+CMGD=" + strconv.Itoa(index)
+}
+
+// CPIN returns the command entering the SIM PIN.
+func CPIN(pin string) string {
+	return "AT+CPIN=" + Quote(pin)
+}
+
+// CSCS returns the command selecting the TE character set, see the
+// Charset constants.
+func CSCS(charset string) string {
+	return "AT+CSCS=" + Quote(charset)
+}
@@ -0,0 +1,51 @@
+package at_test
+
+import (
+	"testing"
+
+	"i4.energy/across/smsgw/at"
+)
+
+func TestCommandBuilders(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{got: at.CMGS("+306912345678"), want: `AT+CMGS="+306912345678"`},
+		{got: at.CMGSPDU(21), want: "AT+CMGS=21"},
+		{got: at.CMGW("+306912345678"), want: `AT+CMGW="+306912345678"`},
+		{got: at.CMGWPDU(19), want: "AT+CMGW=19"},
+		{got: at.CMSS(7), want: "AT+CMSS=7"},
+		{got: at.CMGR(3), want: "AT+CMGR=3"},
+		{got: at.CMGD(3), want: "AT+CMGD=3"},
+		{got: at.CPIN("1234"), want: `AT+CPIN="1234"`},
+		{got: at.CSCS(at.CharsetUCS2), want: `AT+CSCS="UCS2"`},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, tt.got)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Plain", in: "SM", want: `"SM"`},
+		{name: "Empty", in: "", want: `""`},
+		{name: "Quote cannot end the parameter", in: `123";+CMGD=1,4;"`, want: `"123\22;+CMGD=1,4;\22"`},
+		{name: "Backslash", in: `a\b`, want: `"a\5Cb"`},
+		{name: "Line end cannot end the command", in: "1\r\nAT+CFUN=0", want: `"1\0D\0AAT+CFUN=0"`},
+		{name: "Ctrl-Z", in: "1\x1a", want: `"1\1A"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := at.Quote(tt.in); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
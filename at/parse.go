@@ -0,0 +1,246 @@
+package at
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformedResponse is returned by the Parse functions when the response
+// lacks the expected line or its parameters do not follow the grammar.
+var ErrMalformedResponse = errors.New("malformed response")
+
+// SplitFields splits a comma separated parameter list, keeping commas
+// inside double quotes and removing the quotes. Empty parameters are kept,
+// so positions match the grammar of the response.
+//
+//	SplitFields(`"+306912345678",,"24/01/15,12:43:00+08"`)
+//	// ["+306912345678" "" "24/01/15,12:43:00+08"]
+func SplitFields(s string) []string {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+	)
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			fields = append(fields, strings.TrimSpace(field.String()))
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+	return append(fields, strings.TrimSpace(field.String()))
+}
+
+// Params returns the parameters of the first line of resp starting with
+// prefix, such as `0,"SM"` for the prefix "+CPMS:". ok is false if no line
+// starts with prefix.
+func Params(resp, prefix string) (params string, ok bool) {
+	for line := range strings.Lines(resp) {
+		if rest, found := strings.CutPrefix(strings.TrimSpace(line), prefix); found {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return "", false
+}
+
+// fields returns the split parameters of the prefix line in resp, failing
+// if there are fewer than min.
+func fields(resp, prefix string, min int) ([]string, error) {
+	params, ok := Params(resp, prefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s line in %q", ErrMalformedResponse, prefix, resp)
+	}
+	f := SplitFields(params)
+	if len(f) < min {
+		return nil, fmt.Errorf("%w: %s %s", ErrMalformedResponse, prefix, params)
+	}
+	return f, nil
+}
+
+// integers converts the given fields to integers.
+func integers(f []string, targets ...*int) error {
+	for i, target := range targets {
+		n, err := strconv.Atoi(f[i])
+		if err != nil {
+			return fmt.Errorf("%w: parameter %d %q is not a number", ErrMalformedResponse, i+1, f[i])
+		}
+		*target = n
+	}
+	return nil
+}
+
+// CSQ is the signal quality reported by AT+CSQ.
+type CSQ struct {
+	// RSSI is 0 (-113 dBm) to 31 (-51 dBm or more), 99 if not known
+	RSSI int
+	// BER is the bit error rate class from 0 to 7, 99 if not known
+	BER int
+}
+
+// ParseCSQ parses the response to AT+CSQ, "+CSQ: <rssi>,<ber>".
+func ParseCSQ(resp string) (CSQ, error) {
+	f, err := fields(resp, UrcSignalStrength, 2)
+	if err != nil {
+		return CSQ{}, err
+	}
+	var csq CSQ
+	return csq, integers(f, &csq.RSSI, &csq.BER)
+}
+
+// CREG is the network registration reported by AT+CREG? or the +CREG URC.
+type CREG struct {
+	// Stat is the registration state, 1 registered at home and 5 roaming
+	Stat int
+	// LAC and CellID are the hexadecimal location area code and cell ID,
+	// empty unless enabled with AT+CREG=2
+	LAC    string
+	CellID string
+	// AcT is the access technology, -1 if not reported
+	AcT int
+}
+
+// ParseCREG parses the response to AT+CREG?, "+CREG: <n>,<stat>[,<lac>,
+// <ci>[,<AcT>]]", as well as the URC without <n>, "+CREG: <stat>[,<lac>,
+// <ci>[,<AcT>]]". The URC form is told apart by the quoted location area
+// code following <stat>.
+func ParseCREG(resp string) (CREG, error) {
+	params, ok := Params(resp, RegStatus)
+	if !ok {
+		return CREG{}, fmt.Errorf("%w: no %s line in %q", ErrMalformedResponse, RegStatus, resp)
+	}
+	f := SplitFields(params)
+	_, afterFirst, _ := strings.Cut(params, ",")
+	if len(f) > 1 && !strings.HasPrefix(strings.TrimSpace(afterFirst), `"`) {
+		// Drop <n> of the read response
+		f = f[1:]
+	}
+
+	creg := CREG{AcT: -1}
+	if err := integers(f, &creg.Stat); err != nil {
+		return CREG{}, err
+	}
+	if len(f) >= 3 {
+		creg.LAC, creg.CellID = f[1], f[2]
+	}
+	if len(f) >= 4 {
+		if err := integers(f[3:], &creg.AcT); err != nil {
+			return CREG{}, err
+		}
+	}
+	return creg, nil
+}
+
+// CMTI is the notification of a message stored on arrival.
+type CMTI struct {
+	// Storage is the memory the message was stored in, e.g. "SM"
+	Storage string
+	// Index is the location of the message in Storage
+	Index int
+}
+
+// ParseCMTI parses the URC "+CMTI: <mem>,<index>".
+func ParseCMTI(urc string) (CMTI, error) {
+	f, err := fields(urc, UrcNewMsg, 2)
+	if err != nil {
+		return CMTI{}, err
+	}
+	cmti := CMTI{Storage: f[0]}
+	return cmti, integers(f[1:], &cmti.Index)
+}
+
+// CMGRHeader is the header line of a message read in text mode.
+type CMGRHeader struct {
+	// Status is the storage status, e.g. "REC UNREAD"
+	Status string
+	// Sender is the originating address
+	Sender string
+	// Alpha is the phone book name of the sender, usually empty
+	Alpha string
+	// Time is the service center time stamp
+	Time time.Time
+}
+
+// ParseCMGRHeader parses the header of a received message read with
+// AT+CMGR in text mode, "+CMGR: <stat>,<oa>,[<alpha>],<scts>[,...]". The
+// message text follows on the next lines of the response.
+func ParseCMGRHeader(resp string) (CMGRHeader, error) {
+	f, err := fields(resp, MsgRead, 4)
+	if err != nil {
+		return CMGRHeader{}, err
+	}
+	t, err := ParseTimestamp(f[3])
+	if err != nil {
+		return CMGRHeader{}, err
+	}
+	return CMGRHeader{Status: f[0], Sender: f[1], Alpha: f[2], Time: t}, nil
+}
+
+// COPS is the operator selection reported by AT+COPS?.
+type COPS struct {
+	// Mode is 0 for automatic and 1 for manual selection
+	Mode int
+	// Format is the format of Operator: 0 long name, 1 short name, 2
+	// numeric, -1 if not registered
+	Format int
+	// Operator is the network, empty if not registered
+	Operator string
+	// AcT is the access technology, -1 if not reported
+	AcT int
+}
+
+// ParseCOPS parses the response to AT+COPS?, "+COPS: <mode>[,<format>,
+// <oper>[,<AcT>]]".
+func ParseCOPS(resp string) (COPS, error) {
+	f, err := fields(resp, Operator, 1)
+	if err != nil {
+		return COPS{}, err
+	}
+	cops := COPS{Format: -1, AcT: -1}
+	if err := integers(f, &cops.Mode); err != nil {
+		return COPS{}, err
+	}
+	if len(f) >= 3 {
+		if err := integers(f[1:], &cops.Format); err != nil {
+			return COPS{}, err
+		}
+		cops.Operator = f[2]
+	}
+	if len(f) >= 4 {
+		if err := integers(f[3:], &cops.AcT); err != nil {
+			return COPS{}, err
+		}
+	}
+	return cops, nil
+}
+
+// ParseTimestamp parses a text mode service center time stamp,
+// "yy/MM/dd,hh:mm:ss±zz" with the time zone in quarter hours. Without a
+// time zone the time stamp is taken as UTC.
+func ParseTimestamp(s string) (time.Time, error) {
+	const layout = "06/01/02,15:04:05"
+	if len(s) < len(layout) {
+		return time.Time{}, fmt.Errorf("%w: time stamp %q", ErrMalformedResponse, s)
+	}
+	t, err := time.Parse(layout, s[:len(layout)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: time stamp %q", ErrMalformedResponse, s)
+	}
+	zone := s[len(layout):]
+	if zone == "" {
+		return t, nil
+	}
+
+	quarters, err := strconv.Atoi(zone)
+	if err != nil || (zone[0] != '+' && zone[0] != '-') {
+		return time.Time{}, fmt.Errorf("%w: time zone in time stamp %q", ErrMalformedResponse, s)
+	}
+	loc := time.FixedZone("", quarters*15*60)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+}
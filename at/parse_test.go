@@ -0,0 +1,119 @@
+package at_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"i4.energy/across/smsgw/at"
+)
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "15,99", want: []string{"15", "99"}},
+		{in: ` "+306912345678",,"24/01/15,12:43:00+08"`, want: []string{"+306912345678", "", "24/01/15,12:43:00+08"}},
+		{in: `"SC", 3, 10`, want: []string{"SC", "3", "10"}},
+		{in: "", want: []string{""}},
+	}
+	for _, tt := range tests {
+		if got := at.SplitFields(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("SplitFields(%q): expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	if params, ok := at.Params("\r\n+CSCS: \"GSM\"\nOK", at.Charset); !ok || params != `"GSM"` {
+		t.Errorf("expected \"GSM\", got %q (ok %t)", params, ok)
+	}
+	if _, ok := at.Params("OK", at.Charset); ok {
+		t.Error("expected no parameters without a +CSCS line")
+	}
+}
+
+func TestParseCSQ(t *testing.T) {
+	csq, err := at.ParseCSQ("+CSQ: 18,99\nOK")
+	if err != nil || csq != (at.CSQ{RSSI: 18, BER: 99}) {
+		t.Errorf("expected RSSI 18 and BER 99, got %+v (err: %v)", csq, err)
+	}
+
+	for _, resp := range []string{"OK", "+CSQ: 18", "+CSQ: high,99"} {
+		if _, err := at.ParseCSQ(resp); !errors.Is(err, at.ErrMalformedResponse) {
+			t.Errorf("%q: expected ErrMalformedResponse, got: %v", resp, err)
+		}
+	}
+}
+
+func TestParseCREG(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+		want at.CREG
+	}{
+		{name: "Read response", resp: "+CREG: 0,1\nOK", want: at.CREG{Stat: 1, AcT: -1}},
+		{name: "Read response with location", resp: `+CREG: 2,5,"1A2B","01C3D4",7`, want: at.CREG{Stat: 5, LAC: "1A2B", CellID: "01C3D4", AcT: 7}},
+		{name: "URC", resp: "+CREG: 2", want: at.CREG{Stat: 2, AcT: -1}},
+		{name: "URC with location", resp: `+CREG: 1,"1A2B","01C3D4",7`, want: at.CREG{Stat: 1, LAC: "1A2B", CellID: "01C3D4", AcT: 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := at.ParseCREG(tt.resp)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := at.ParseCREG("+CREG: 0,x"); !errors.Is(err, at.ErrMalformedResponse) {
+		t.Errorf("expected ErrMalformedResponse, got: %v", err)
+	}
+}
+
+func TestParseCMTI(t *testing.T) {
+	cmti, err := at.ParseCMTI(`+CMTI: "SM",12`)
+	if err != nil || cmti != (at.CMTI{Storage: "SM", Index: 12}) {
+		t.Errorf("expected SM index 12, got %+v (err: %v)", cmti, err)
+	}
+	if _, err := at.ParseCMTI(`+CMTI: "SM"`); !errors.Is(err, at.ErrMalformedResponse) {
+		t.Errorf("expected ErrMalformedResponse, got: %v", err)
+	}
+}
+
+func TestParseCMGRHeader(t *testing.T) {
+	h, err := at.ParseCMGRHeader("+CMGR: \"REC UNREAD\",\"+306912345678\",,\"24/01/15,12:43:00+08\"\nHello\nOK")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 1, 15, 12, 43, 0, 0, time.FixedZone("", 2*3600))
+	if h.Status != "REC UNREAD" || h.Sender != "+306912345678" || h.Alpha != "" || !h.Time.Equal(want) {
+		t.Errorf("unexpected header %+v", h)
+	}
+
+	if _, err := at.ParseCMGRHeader(`+CMGR: "REC READ","+306912345678",,"yesterday"`); !errors.Is(err, at.ErrMalformedResponse) {
+		t.Errorf("expected ErrMalformedResponse, got: %v", err)
+	}
+}
+
+func TestParseCOPS(t *testing.T) {
+	tests := []struct {
+		resp string
+		want at.COPS
+	}{
+		{resp: `+COPS: 0,0,"COSMOTE",7`, want: at.COPS{Mode: 0, Format: 0, Operator: "COSMOTE", AcT: 7}},
+		{resp: `+COPS: 1,2,"20201"`, want: at.COPS{Mode: 1, Format: 2, Operator: "20201", AcT: -1}},
+		{resp: "+COPS: 0", want: at.COPS{Format: -1, AcT: -1}},
+	}
+	for _, tt := range tests {
+		got, err := at.ParseCOPS(tt.resp)
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v (err: %v)", tt.resp, tt.want, got, err)
+		}
+	}
+}
@@ -42,24 +42,49 @@ func Splitter(data []byte, atEOF bool) (advance int, token []byte, err error) {
 var _ bufio.SplitFunc = Splitter
 
 // Classify identifies the nature of the modem output
+//
+// Most tokens are data lines, e.g. the message listing of an inbox dump, so
+// Classify dispatches on the first characters and only compares the
+// candidates those leave instead of testing every known prefix.
 func Classify(line string) ResponseType {
-	if line == Prompt {
-		return TypePrompt
-	}
-
-	// Direct matches for final results
-	switch line {
-	case OK, ERROR, NoCarrier, NoDialtone, Busy, NoAnswer:
-		return TypeFinal
+	if line == "" {
+		return TypeData
 	}
 
-	// Prefix matches
-	switch {
-	case strings.HasPrefix(line, CmeError), strings.HasPrefix(line, CmsError):
-		return TypeFinal
-	case strings.HasPrefix(line, UrcNewMsg), line == UrcCall:
-		return TypeURC
-	default:
-		return TypeData
+	switch line[0] {
+	case '>':
+		if line == Prompt {
+			return TypePrompt
+		}
+	case 'O', 'E', 'N', 'B':
+		// Direct matches for final results
+		switch line {
+		case OK, ERROR, NoCarrier, NoDialtone, Busy, NoAnswer:
+			return TypeFinal
+		}
+	case 'R':
+		if line == UrcCall {
+			return TypeURC
+		}
+	case '+':
+		// Prefix matches, all of the form "+CM?"
+		if len(line) < 4 || line[1] != 'C' || line[2] != 'M' {
+			return TypeData
+		}
+		switch line[3] {
+		case 'E':
+			if strings.HasPrefix(line, CmeError) {
+				return TypeFinal
+			}
+		case 'S':
+			if strings.HasPrefix(line, CmsError) {
+				return TypeFinal
+			}
+		case 'T':
+			if strings.HasPrefix(line, UrcNewMsg) || strings.HasPrefix(line, UrcNewMsgDirect) {
+				return TypeURC
+			}
+		}
 	}
+	return TypeData
 }
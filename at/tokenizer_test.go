@@ -144,6 +144,7 @@ func TestClassify(t *testing.T) {
 		// URCs
 		{name: "New message URC", input: "+CMTI: \"SM\",1", expected: at.TypeURC},
 		{name: "Incoming call URC", input: "RING", expected: at.TypeURC},
+		{name: "Direct delivery URC", input: "+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"", expected: at.TypeURC},
 
 		// Data responses
 		{name: "AT command", input: "AT+CSQ", expected: at.TypeData},
@@ -152,6 +153,10 @@ func TestClassify(t *testing.T) {
 		{name: "Network registration", input: "+CREG: 0,1", expected: at.TypeData},
 		{name: "SMS send result", input: "+CMGS: 123", expected: at.TypeData},
 		{name: "Device info", input: "Quectel", expected: at.TypeData},
+		{name: "Message listing", input: "+CMGL: 1,\"REC READ\",\"+1234567890\",,\"24/01/15,10:30:00+08\"", expected: at.TypeData},
+		{name: "Message text", input: "OK, pump 4 is back", expected: at.TypeData},
+		{name: "Truncated prefix", input: "+CM", expected: at.TypeData},
+		{name: "Empty line", input: "", expected: at.TypeData},
 
 		// Prompt
 		{name: "SMS input prompt", input: "> ", expected: at.TypePrompt},
@@ -166,3 +171,44 @@ func TestClassify(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkClassify classifies the tokens of an inbox dump, which are
+// mostly data lines.
+func BenchmarkClassify(b *testing.B) {
+	tokens := []string{
+		"+CMGL: 1,\"REC READ\",\"+1234567890\",,\"24/01/15,10:30:00+08\"",
+		"Pump 4 pressure low",
+		"+CMGL: 2,\"REC UNREAD\",\"+1234567890\",,\"24/01/15,10:31:00+08\"",
+		"Pump 4 pressure normal",
+		"",
+		"+CMTI: \"SM\",3",
+		"OK",
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, token := range tokens {
+			at.Classify(token)
+		}
+	}
+}
+
+func TestHasPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Direct delivery header", input: "+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"", expected: true},
+		{name: "Stored message indication", input: "+CMTI: \"SM\",1", expected: false},
+		{name: "Incoming call", input: "RING", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := at.HasPayload(tt.input); got != tt.expected {
+				t.Errorf("Expected %v, got %v for input %q", tt.expected, got, tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package at
+
+import "strings"
+
+// URCFrame is a framing rule for a URC whose header line is followed by
+// payload lines that belong to it. The payload must not be classified on
+// its own, since it may look like any other response (message text "OK").
+type URCFrame struct {
+	// Prefix identifies the URC header line (e.g. "+CMT:")
+	Prefix string
+	// Lines is the number of payload lines following the header
+	Lines int
+	// UntilBlank collects payload lines up to the next empty line instead of
+	// a fixed number of lines. With verbose result codes (ATV1) every
+	// response starts with an empty line, which terminates the URC.
+	UntilBlank bool
+}
+
+// DefaultURCFrames are the framing rules for standard multi-line URCs.
+var DefaultURCFrames = []URCFrame{
+	// +CMT: <oa>,[<alpha>],<scts> followed by the message body
+	{Prefix: UrcNewMsgDirect, Lines: 1},
+}
+
+// HasPayload reports whether the URC line is a header followed by payload
+// lines according to DefaultURCFrames, such as the message body after a
+// +CMT header in direct delivery mode.
+func HasPayload(line string) bool {
+	return NewURCAssembler(DefaultURCFrames).Begin(line)
+}
+
+// URCAssembler combines a framed URC and its payload lines into a single
+// event. Lines are joined with "\n". It is not safe for concurrent use.
+//
+//	if assembler.Pending() {
+//		if urc, ok := assembler.Add(token); ok {
+//			// complete URC
+//		}
+//	} else if assembler.Begin(token) {
+//		// framed URC header, payload follows
+//	}
+type URCAssembler struct {
+	frames []URCFrame
+	frame  *URCFrame
+	lines  []string
+	// prompt holds a "> " the Splitter cut off the start of a payload line
+	prompt string
+}
+
+// NewURCAssembler returns an assembler using the given framing rules.
+func NewURCAssembler(frames []URCFrame) *URCAssembler {
+	return &URCAssembler{frames: frames}
+}
+
+// Begin starts assembling a URC if line is the header of a framed URC and
+// reports whether it did. Following tokens must then be passed to Add until
+// it reports the URC as complete.
+func (a *URCAssembler) Begin(line string) bool {
+	for i, f := range a.frames {
+		if (f.Lines > 0 || f.UntilBlank) && strings.HasPrefix(line, f.Prefix) {
+			a.frame = &a.frames[i]
+			a.lines = []string{line}
+			return true
+		}
+	}
+	return false
+}
+
+// Pending reports whether a URC is being assembled.
+func (a *URCAssembler) Pending() bool {
+	return a.frame != nil
+}
+
+// Add appends a payload token to the pending URC. It returns the complete
+// URC once all of its lines arrived.
+func (a *URCAssembler) Add(token string) (string, bool) {
+	if a.frame == nil {
+		return "", false
+	}
+	if token == Prompt {
+		// The rest of the payload line follows as the next token
+		a.prompt += token
+		return "", false
+	}
+	line := a.prompt + token
+	a.prompt = ""
+
+	if a.frame.UntilBlank {
+		if line == "" {
+			return a.flush(), true
+		}
+		a.lines = append(a.lines, line)
+		return "", false
+	}
+
+	a.lines = append(a.lines, line)
+	if len(a.lines)-1 >= a.frame.Lines {
+		return a.flush(), true
+	}
+	return "", false
+}
+
+// Reset discards the pending URC, if any.
+func (a *URCAssembler) Reset() {
+	a.frame, a.lines, a.prompt = nil, nil, ""
+}
+
+func (a *URCAssembler) flush() string {
+	urc := strings.Join(a.lines, "\n")
+	a.Reset()
+	return urc
+}
@@ -0,0 +1,76 @@
+package at_test
+
+import (
+	"testing"
+
+	"i4.energy/across/smsgw/at"
+)
+
+func TestURCAssembler(t *testing.T) {
+	frames := []at.URCFrame{
+		{Prefix: "+CMT:", Lines: 1},
+		{Prefix: "^REPORT:", Lines: 2},
+		{Prefix: "+CUSD:", UntilBlank: true},
+	}
+
+	tests := []struct {
+		name     string
+		tokens   []string
+		expected []string
+	}{
+		{
+			name:     "Fixed number of payload lines",
+			tokens:   []string{"^REPORT: 1", "a", "b", "^REPORT: 2", "OK", "ERROR"},
+			expected: []string{"^REPORT: 1\na\nb", "^REPORT: 2\nOK\nERROR"},
+		},
+		{
+			name:     "Payload up to blank line",
+			tokens:   []string{"+CUSD: 0,\"Balance", "12.50 EUR", "valid until 01/03\",15", "", "+CUSD: 2", ""},
+			expected: []string{"+CUSD: 0,\"Balance\n12.50 EUR\nvalid until 01/03\",15", "+CUSD: 2"},
+		},
+		{
+			name:     "Empty payload line",
+			tokens:   []string{"+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"", ""},
+			expected: []string{"+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"\n"},
+		},
+		{
+			name:     "Payload line split at prompt",
+			tokens:   []string{"+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"", "> ", "quoted"},
+			expected: []string{"+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"\n> quoted"},
+		},
+		{
+			name:     "Unframed lines are left alone",
+			tokens:   []string{"+CMTI: \"SM\",1", "RING", "OK"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assembler := at.NewURCAssembler(frames)
+			var urcs []string
+			for _, token := range tt.tokens {
+				if assembler.Pending() {
+					if urc, ok := assembler.Add(token); ok {
+						urcs = append(urcs, urc)
+					}
+					continue
+				}
+				assembler.Begin(token)
+			}
+
+			if len(urcs) != len(tt.expected) {
+				t.Fatalf("Expected %d URCs, got %d.\nExpected: %q\nGot: %q",
+					len(tt.expected), len(urcs), tt.expected, urcs)
+			}
+			for i, expected := range tt.expected {
+				if urcs[i] != expected {
+					t.Errorf("URC %d: expected %q, got %q", i, expected, urcs[i])
+				}
+			}
+			if assembler.Pending() {
+				t.Error("Expected no pending URC")
+			}
+		})
+	}
+}
@@ -2,11 +2,13 @@ module i4.energy/across/smsgw
 
 go 1.25.5
 
-require go.bug.st/serial v1.6.4
+require (
+	go.bug.st/serial v1.6.4
+	go.uber.org/mock v0.6.0
+)
 
 require (
 	github.com/creack/goselect v0.1.2 // indirect
-	go.uber.org/mock v0.6.0 // indirect
 	golang.org/x/mod v0.27.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect
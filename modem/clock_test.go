@@ -0,0 +1,100 @@
+package modem_test
+
+import (
+	"sync"
+	"time"
+
+	"i4.energy/across/smsgw/modem"
+)
+
+// fakeClock is a modem.Clock whose time only moves when Advance is called.
+// Tickers and timers fire synchronously from Advance, dropping ticks that
+// are not consumed in time just like the time package does.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	// created receives a value whenever a ticker or timer is created
+	created chan struct{}
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		created: make(chan struct{}, 16),
+	}
+}
+
+// fakeWaiter backs both fake tickers and timers. A zero period marks a timer.
+type fakeWaiter struct {
+	clock   *fakeClock
+	c       chan time.Time
+	next    time.Time
+	period  time.Duration
+	stopped bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) modem.Ticker {
+	return fakeTicker{c.add(d, d)}
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) modem.Timer {
+	return c.add(d, 0)
+}
+
+func (c *fakeClock) add(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1), next: c.now.Add(d), period: period}
+	c.waiters = append(c.waiters, w)
+	c.mu.Unlock()
+
+	c.created <- struct{}{}
+	return w
+}
+
+// Advance moves the clock forward by d and fires all due tickers and timers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, w := range c.waiters {
+		for !w.stopped && !w.next.After(c.now) {
+			select {
+			case w.c <- w.next:
+			default:
+			}
+			if w.period == 0 {
+				w.stopped = true
+				break
+			}
+			w.next = w.next.Add(w.period)
+		}
+	}
+}
+
+// WaitForWaiter blocks until a ticker or timer has been created.
+func (c *fakeClock) WaitForWaiter() {
+	<-c.created
+}
+
+func (w *fakeWaiter) C() <-chan time.Time { return w.c }
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	wasActive := !w.stopped
+	w.stopped = true
+	return wasActive
+}
+
+// fakeTicker adapts fakeWaiter to the modem.Ticker interface.
+type fakeTicker struct{ *fakeWaiter }
+
+func (t fakeTicker) Stop() { t.fakeWaiter.Stop() }
@@ -1,22 +1,133 @@
 package modem
 
 import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"time"
+
+	"i4.energy/across/smsgw/at"
 )
 
+// Config holds the settings of a Modem. It is created with ConfigBuilder
+// and cannot be changed afterwards: New takes a snapshot of it, so the
+// Modem never observes later modifications of the builder.
+//
+// Settings can be overridden in two ways. Per operation, a deadline on the
+// context passed to a Modem method replaces the command timeouts. For the modem
+// as a whole, Builder derives a new Config to create a new Modem with.
 type Config struct {
 	// dialer is the interface used to establish connection to the modem
 	dialer Dialer
 	// simPIN is the PIN code for SIM card authentication (optional)
 	simPIN string
+	// simPINFile is a file the SIM PIN is read from by Build (optional)
+	simPINFile string
 	// minSendInterval is the minimum time to wait between sending SMS messages
 	minSendInterval time.Duration
+	// sandboxRecipient receives all messages instead of their recipients
+	// (optional)
+	sandboxRecipient string
+	// moreMessages keeps the link open between queued messages (AT+CMMS)
+	moreMessages bool
 	// maxRetries is the maximum number of retry attempts for failed operations
 	maxRetries int
+	// readerRestarts is how often the Loop restarts a panicked reader
+	readerRestarts int
 	// atTimeout is the timeout duration for individual AT command responses
 	atTimeout time.Duration
+	// timeouts overrides the timeouts of command classes
+	timeouts map[CommandClass]time.Duration
 	// initTimeout is the timeout duration for modem initialization sequence
 	initTimeout time.Duration
+	// clock is the time source for polling and pacing
+	clock Clock
+	// simReadyPolling controls how long to wait for the SIM after PIN entry
+	simReadyPolling PollConfig
+	// initProgress receives progress reports during initialization (optional)
+	initProgress func(InitProgress)
+	// registrationWait enables waiting for network registration during init
+	registrationWait bool
+	// registrationPolling controls how long to wait for network registration
+	registrationPolling PollConfig
+	// charset is the TE character set selected with AT+CSCS
+	charset string
+	// newMessageMode selects how incoming messages are reported
+	newMessageMode NewMessageMode
+	// profileReset selects the profile restored before configuration
+	profileReset ProfileReset
+	// saveProfile stores the configured settings in NVRAM after init
+	saveProfile bool
+	// urcFrames are framing rules for multi-line URCs besides the defaults
+	urcFrames []at.URCFrame
+	// classifier classifies the lines the modem outputs
+	classifier at.Classifier
+	// urcLimits are the rate limits of URC floods
+	urcLimits []urcLimit
+	// historySize is the number of lifecycle events kept
+	historySize int
+	// driftCheck is the interval of the configuration drift check
+	driftCheck time.Duration
+	// logger receives protocol diagnostics (optional)
+	logger *slog.Logger
+	// metrics receives telemetry (optional)
+	metrics Metrics
+	// diagnostics receives the loop events (optional)
+	diagnostics func(Diagnostic)
+	// orphanResync is the number of consecutive orphaned responses that
+	// triggers a resync, zero disables it
+	orphanResync int
+	// keepalive is the idle time after which the Loop pings the modem,
+	// zero disables it
+	keepalive time.Duration
+	// drainWindow is the quiet period that ends draining stale input
+	// before init, zero disables draining
+	drainWindow time.Duration
+}
+
+// Builder returns a ConfigBuilder initialized with the settings of c, to
+// derive a configuration that overrides some of them.
+func (c Config) Builder() *ConfigBuilder {
+	return &ConfigBuilder{config: c.clone()}
+}
+
+// clone returns a copy of c that shares no mutable state with it.
+func (c Config) clone() Config {
+	c.urcFrames = slices.Clone(c.urcFrames)
+	c.timeouts = maps.Clone(c.timeouts)
+	c.urcLimits = slices.Clone(c.urcLimits)
+	return c
+}
+
+// snapshot returns the copy of c a Modem runs with, with defaults filled
+// in for settings that are optional.
+func (c Config) snapshot() Config {
+	c = c.clone()
+	if c.clock == nil {
+		c.clock = realClock{}
+	}
+	if c.logger == nil {
+		c.logger = slog.New(slog.DiscardHandler)
+	}
+	if c.metrics == nil {
+		c.metrics = NopMetrics{}
+	}
+	if c.charset == "" {
+		c.charset = at.CharsetGSM
+	}
+	if c.classifier == nil {
+		c.classifier = at.DefaultClassifier
+	}
+	return c
+}
+
+// allURCFrames returns the configured URC framing rules together with
+// at.DefaultURCFrames.
+func (c Config) allURCFrames() []at.URCFrame {
+	return slices.Concat(at.DefaultURCFrames, c.urcFrames)
 }
 
 // ConfigBuilder provides a fluent API for building modem configurations
@@ -28,10 +139,16 @@ type ConfigBuilder struct {
 func NewConfigBuilder() *ConfigBuilder {
 	return &ConfigBuilder{
 		config: Config{
-			minSendInterval: time.Minute / 30,
-			maxRetries:      3,
-			atTimeout:       5 * time.Second,
-			initTimeout:     30 * time.Second,
+			minSendInterval:  time.Minute / 30,
+			maxRetries:       3,
+			readerRestarts:   3,
+			atTimeout:        5 * time.Second,
+			initTimeout:      30 * time.Second,
+			clock:            realClock{},
+			registrationWait: true,
+			charset:          at.CharsetGSM,
+			drainWindow:      100 * time.Millisecond,
+			historySize:      defaultHistorySize,
 		},
 	}
 }
@@ -48,7 +165,36 @@ func (b *ConfigBuilder) WithSimPIN(pin string) *ConfigBuilder {
 	return b
 }
 
-// WithMinSendInterval sets the minimum interval between SMS sends
+// WithSimPINFile reads the SIM PIN from a file when the configuration is
+// built, such as a Docker or Kubernetes secret mount, so the PIN does not
+// have to be passed in the environment or on the command line. Surrounding
+// whitespace is ignored. It takes precedence over WithSimPIN.
+func (b *ConfigBuilder) WithSimPINFile(path string) *ConfigBuilder {
+	b.config.simPINFile = path
+	return b
+}
+
+// WithSandboxRecipient redirects all messages to the given test number, so
+// staging environments can use real hardware without reaching customers.
+// The original recipient is appended to text messages on a line of its
+// own, binary messages are redirected as is. Empty disables the sandbox.
+func (b *ConfigBuilder) WithSandboxRecipient(recipient string) *ConfigBuilder {
+	b.config.sandboxRecipient = recipient
+	return b
+}
+
+// WithMoreMessagesToSend enables the throughput mode for bulk sends: while
+// more messages are queued, the modem is asked to keep the radio link open
+// between them (AT+CMMS=1) instead of setting it up for each message. It
+// only pays off if the minimum send interval is shorter than the few
+// seconds the modem keeps the link. Modems rejecting AT+CMMS send as usual.
+func (b *ConfigBuilder) WithMoreMessagesToSend(enabled bool) *ConfigBuilder {
+	b.config.moreMessages = enabled
+	return b
+}
+
+// WithMinSendInterval sets the minimum interval between SMS sends. Sends
+// are delayed until their turn, zero disables pacing.
 func (b *ConfigBuilder) WithMinSendInterval(interval time.Duration) *ConfigBuilder {
 	b.config.minSendInterval = interval
 	return b
@@ -60,24 +206,253 @@ func (b *ConfigBuilder) WithMaxRetries(retries int) *ConfigBuilder {
 	return b
 }
 
-// WithATTimeout sets the timeout for AT commands
+// WithReaderRestarts sets how often the Loop restarts the transport reader
+// after a panic before it gives up and returns a *PanicError. Zero stops
+// the Loop on the first panic.
+func (b *ConfigBuilder) WithReaderRestarts(restarts int) *ConfigBuilder {
+	b.config.readerRestarts = restarts
+	return b
+}
+
+// WithATTimeout sets the timeout for AT commands, see CommandGeneral
 func (b *ConfigBuilder) WithATTimeout(timeout time.Duration) *ConfigBuilder {
 	b.config.atTimeout = timeout
 	return b
 }
 
+// WithCommandTimeout sets the timeout for the commands of class. The AT
+// timeout applies to CommandGeneral, the other classes default to the
+// maximum response times modem vendors document: 120 seconds for
+// CommandMessage and 180 seconds for CommandNetworkScan. Zero disables
+// the timeout of the class. A deadline of the caller's context takes
+// precedence.
+func (b *ConfigBuilder) WithCommandTimeout(class CommandClass, timeout time.Duration) *ConfigBuilder {
+	if class == CommandGeneral {
+		return b.WithATTimeout(timeout)
+	}
+	if b.config.timeouts == nil {
+		b.config.timeouts = make(map[CommandClass]time.Duration)
+	}
+	b.config.timeouts[class] = timeout
+	return b
+}
+
 // WithInitTimeout sets the timeout for modem initialization
 func (b *ConfigBuilder) WithInitTimeout(timeout time.Duration) *ConfigBuilder {
 	b.config.initTimeout = timeout
 	return b
 }
 
+// WithDrainWindow sets how long the modem must stay quiet before stale
+// input, such as boot URCs or partial lines, counts as drained before
+// initialization (default 100ms). Draining needs a transport supporting
+// read timeouts like a serial port and is skipped for others. Zero
+// disables it.
+func (b *ConfigBuilder) WithDrainWindow(window time.Duration) *ConfigBuilder {
+	b.config.drainWindow = window
+	return b
+}
+
+// WithSIMReadyPolling sets how the SIM status is polled after entering the
+// PIN. Zero fields fall back to the defaults described on PollConfig.
+func (b *ConfigBuilder) WithSIMReadyPolling(config PollConfig) *ConfigBuilder {
+	b.config.simReadyPolling = config
+	return b
+}
+
+// WithRegistrationWait enables or disables waiting for network registration
+// at the end of initialization (enabled by default)
+func (b *ConfigBuilder) WithRegistrationWait(enabled bool) *ConfigBuilder {
+	b.config.registrationWait = enabled
+	return b
+}
+
+// WithRegistrationPolling sets how the network registration status is polled
+// during initialization. Zero fields fall back to the defaults described on
+// PollConfig.
+func (b *ConfigBuilder) WithRegistrationPolling(config PollConfig) *ConfigBuilder {
+	b.config.registrationPolling = config
+	return b
+}
+
+// WithCharset sets the character set selected with AT+CSCS during
+// initialization: at.CharsetGSM (default), at.CharsetIRA or at.CharsetUCS2.
+// With UCS2, text mode parameters and messages are hex encoded as UTF-16,
+// with GSM and IRA they are written as is. Either way the modem sends text
+// mode messages in the GSM 7-bit alphabet, so messages with characters not
+// coded alike in ASCII and that alphabet are sent in PDU mode instead, see
+// SendSMS.
+func (b *ConfigBuilder) WithCharset(charset string) *ConfigBuilder {
+	b.config.charset = charset
+	return b
+}
+
+// WithNewMessageMode selects how the modem reports incoming messages. By
+// default the modem's setting is left untouched.
+func (b *ConfigBuilder) WithNewMessageMode(mode NewMessageMode) *ConfigBuilder {
+	b.config.newMessageMode = mode
+	return b
+}
+
+// WithProfileReset restores the stored profile (ATZ) or the factory
+// defaults (AT&F) before the modem is configured, so initialization starts
+// from known settings regardless of what a previous process left behind
+// (echo, PDU mode, new message indication). By default the current settings
+// are kept.
+func (b *ConfigBuilder) WithProfileReset(reset ProfileReset) *ConfigBuilder {
+	b.config.profileReset = reset
+	return b
+}
+
+// WithSaveProfile enables saving the configured settings to the modem's
+// NVRAM (AT&W) at the end of initialization, so a brief modem-side reset
+// keeps them until the gateway initializes the modem again. Which settings
+// are part of the saved profile depends on the modem.
+func (b *ConfigBuilder) WithSaveProfile(enabled bool) *ConfigBuilder {
+	b.config.saveProfile = enabled
+	return b
+}
+
+// WithURCFrames adds framing rules for multi-line URCs, such as wrapped
+// +CUSD text or vendor reports, to at.DefaultURCFrames. Lines matching a
+// rule are treated as URC headers and delivered together with their payload
+// lines as a single URC.
+func (b *ConfigBuilder) WithURCFrames(frames ...at.URCFrame) *ConfigBuilder {
+	b.config.urcFrames = append(b.config.urcFrames, frames...)
+	return b
+}
+
+// WithURCRateLimit limits the URCs starting with prefix, such as "+CSQ:"
+// or "RING", to one per interval. URCs arriving within the interval are
+// coalesced: only the latest is delivered once the interval passed, the
+// others are reported as EventURCSuppressed. This keeps URC floods from
+// filling the URC channel and crowding out other URCs. A URC is limited by
+// the first matching prefix.
+func (b *ConfigBuilder) WithURCRateLimit(prefix string, interval time.Duration) *ConfigBuilder {
+	b.config.urcLimits = append(b.config.urcLimits, urcLimit{prefix: prefix, interval: interval})
+	return b
+}
+
+// WithHistorySize sets the number of lifecycle events kept, see
+// Modem.History. The default is 64, zero keeps none.
+func (b *ConfigBuilder) WithHistorySize(size int) *ConfigBuilder {
+	b.config.historySize = size
+	return b
+}
+
+// WithClassifier sets how the lines the modem outputs are told apart into
+// URCs, final results and data, at.DefaultClassifier by default. Modems
+// with vendor specific URCs or result codes can be supported by extending
+// it, e.g. with an at.PrefixClassifier.
+func (b *ConfigBuilder) WithClassifier(classifier at.Classifier) *ConfigBuilder {
+	b.config.classifier = classifier
+	return b
+}
+
+// WithLogger sets the logger receiving protocol diagnostics, such as
+// orphaned responses and resynchronization. Nothing is logged by default.
+// The level is checked on every record, so a handler leveled by a
+// slog.LevelVar can switch to debug at runtime without a new Modem.
+func (b *ConfigBuilder) WithLogger(logger *slog.Logger) *ConfigBuilder {
+	b.config.logger = logger
+	return b
+}
+
+// WithMetrics sets the backend receiving the modem's telemetry, see the
+// Metric* constants for what is reported. Nothing is reported by default.
+func (b *ConfigBuilder) WithMetrics(metrics Metrics) *ConfigBuilder {
+	b.config.metrics = metrics
+	return b
+}
+
+// WithDriftCheck makes the Loop verify the settings made during
+// initialization every interval and apply them again if the modem lost
+// them, e.g. after a vendor specific automatic reset. See CheckConfig.
+// Zero, the default, disables the check.
+func (b *ConfigBuilder) WithDriftCheck(interval time.Duration) *ConfigBuilder {
+	b.config.driftCheck = interval
+	return b
+}
+
+// WithDiagnostics sets a callback receiving the irregular conditions the
+// Loop handles on its own, such as dropped URCs or responses nobody was
+// waiting for. It is called from the Loop, except for EventConfigDrift,
+// which is reported on the goroutine running CheckConfig. The callback may
+// therefore run on several goroutines at once, so it must be safe for
+// concurrent use, and it must not block.
+func (b *ConfigBuilder) WithDiagnostics(report func(Diagnostic)) *ConfigBuilder {
+	b.config.diagnostics = report
+	return b
+}
+
+// WithOrphanResync enables resynchronization after the given number of
+// consecutive final responses arrived with no command pending. The Loop then
+// discards queued input and waits for the modem to answer an AT ping before
+// writing further commands. Zero (default) disables it.
+func (b *ConfigBuilder) WithOrphanResync(threshold int) *ConfigBuilder {
+	b.config.orphanResync = threshold
+	return b
+}
+
+// WithKeepalive makes the Loop send an AT ping once the link has been idle
+// for at least the given duration, with no command written and no line
+// received. A modem not answering the ping within the AT timeout is
+// considered gone and the Loop returns ErrKeepaliveTimeout, so the caller
+// can reconnect. The pings also keep some USB modems from falling into an
+// unresponsive sleep state. Zero (default) disables it.
+func (b *ConfigBuilder) WithKeepalive(idle time.Duration) *ConfigBuilder {
+	b.config.keepalive = idle
+	return b
+}
+
+// WithInitProgress sets a callback that receives progress reports while
+// New initializes the modem. The callback is invoked synchronously from
+// New and must not block.
+func (b *ConfigBuilder) WithInitProgress(report func(InitProgress)) *ConfigBuilder {
+	b.config.initProgress = report
+	return b
+}
+
+// WithClock sets the time source used for polling and pacing. The command
+// timeouts, see WithATTimeout and WithCommandTimeout, are context deadlines
+// and always run on real time.
+func (b *ConfigBuilder) WithClock(clock Clock) *ConfigBuilder {
+	b.config.clock = clock
+	return b
+}
+
 // Build validates and returns the final configuration
 func (b *ConfigBuilder) Build() (Config, error) {
 	// Validate the configuration
 	if b.config.dialer == nil {
 		return b.config, ErrNoDialer
 	}
+	switch b.config.charset {
+	case at.CharsetGSM, at.CharsetIRA, at.CharsetUCS2:
+	default:
+		return b.config, fmt.Errorf("%w: %q", ErrUnsupportedCharset, b.config.charset)
+	}
+	if b.config.sandboxRecipient != "" {
+		if err := validateRecipient(b.config.sandboxRecipient); err != nil {
+			return b.config, fmt.Errorf("sandbox recipient: %w", err)
+		}
+	}
+	if err := b.config.simReadyPolling.validate(); err != nil {
+		return b.config, fmt.Errorf("SIM ready polling: %w", err)
+	}
+	if err := b.config.registrationPolling.validate(); err != nil {
+		return b.config, fmt.Errorf("registration polling: %w", err)
+	}
+
+	config := b.config.clone()
+	if config.simPINFile != "" {
+		pin, err := os.ReadFile(config.simPINFile)
+		if err != nil {
+			return b.config, fmt.Errorf("read SIM PIN file: %w", err)
+		}
+		config.simPIN = strings.TrimSpace(string(pin))
+	}
 
-	return b.config, nil
+	// The builder may be reused, the returned Config must not change
+	return config, nil
 }
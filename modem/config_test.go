@@ -1,8 +1,17 @@
 package modem_test
 
 import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/at"
 	"i4.energy/across/smsgw/modem"
 )
 
@@ -14,4 +23,153 @@ func TestConfig(t *testing.T) {
 			t.Errorf("expected ErrNoDialer, got: %v", err)
 		}
 	})
+
+	t.Run("SIM ready polling validation", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			polling modem.PollConfig
+			valid   bool
+		}{
+			{name: "Zero value uses defaults", polling: modem.PollConfig{}, valid: true},
+			{name: "Interval within timeout", polling: modem.PollConfig{Interval: time.Second, Timeout: 10 * time.Second}, valid: true},
+			{name: "Retries without timeout", polling: modem.PollConfig{Interval: time.Second, MaxRetries: 5}, valid: true},
+			{name: "Negative interval", polling: modem.PollConfig{Interval: -time.Second}},
+			{name: "Negative retries", polling: modem.PollConfig{MaxRetries: -1}},
+			{name: "Interval longer than timeout", polling: modem.PollConfig{Interval: time.Minute, Timeout: time.Second}},
+			{name: "Interval within default timeout", polling: modem.PollConfig{Interval: 10 * time.Second}, valid: true},
+			{name: "Interval longer than default timeout", polling: modem.PollConfig{Interval: 40 * time.Second}},
+			{name: "Timeout shorter than default interval", polling: modem.PollConfig{Timeout: 100 * time.Millisecond}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				_, err := modem.NewConfigBuilder().
+					WithDialer(modem.NewMockDialer(ctrl)).
+					WithSIMReadyPolling(tt.polling).
+					Build()
+
+				if tt.valid && err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if !tt.valid && !errors.Is(err, modem.ErrInvalidPollConfig) {
+					t.Errorf("expected ErrInvalidPollConfig, got: %v", err)
+				}
+			})
+		}
+	})
+	t.Run("ErrUnsupportedCharset for unknown character set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, err := modem.NewConfigBuilder().
+			WithDialer(modem.NewMockDialer(ctrl)).
+			WithCharset("8859-1").
+			Build()
+
+		if !errors.Is(err, modem.ErrUnsupportedCharset) {
+			t.Errorf("expected ErrUnsupportedCharset, got: %v", err)
+		}
+	})
+
+	t.Run("ErrInvalidRecipient for invalid sandbox recipient", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, err := modem.NewConfigBuilder().
+			WithDialer(modem.NewMockDialer(ctrl)).
+			WithSandboxRecipient("staging").
+			Build()
+
+		if !errors.Is(err, modem.ErrInvalidRecipient) {
+			t.Errorf("expected ErrInvalidRecipient, got: %v", err)
+		}
+	})
+
+	t.Run("Built config is not affected by later builder changes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		builder := modem.NewConfigBuilder().
+			WithDialer(modem.NewMockDialer(ctrl)).
+			WithCharset(at.CharsetUCS2)
+		config, err := builder.Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		builder.WithCharset("8859-1")
+		if _, err := builder.Build(); !errors.Is(err, modem.ErrUnsupportedCharset) {
+			t.Errorf("expected ErrUnsupportedCharset, got: %v", err)
+		}
+
+		// Deriving from the built config starts from its own settings
+		if _, err := config.Builder().WithMaxRetries(1).Build(); err != nil {
+			t.Errorf("unexpected error from derived Build(): %v", err)
+		}
+	})
+
+	t.Run("Reads the SIM PIN from a file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		path := filepath.Join(t.TempDir(), "sim_pin")
+		if err := os.WriteFile(path, []byte("1234\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		// The PIN from the file is entered, without the trailing newline
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			NewMockSequence(mockTransport).
+				AT().
+				EchoOff().
+				VerboseErrors().
+				SimPinRequired().
+				PINAttempts(3).
+				EnterPIN("1234").
+				SimReady().
+				SMSTextMode().
+				Charset("GSM").
+				Registration("1").
+				Build(),
+		)...)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithSimPINFile(path).
+			WithSIMReadyPolling(modem.PollConfig{Interval: time.Millisecond}).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		m.Close()
+	})
+
+	t.Run("Error on missing SIM PIN file", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, err := modem.NewConfigBuilder().
+			WithDialer(modem.NewMockDialer(ctrl)).
+			WithSimPINFile(filepath.Join(t.TempDir(), "missing")).
+			Build()
+
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("expected fs.ErrNotExist, got: %v", err)
+		}
+	})
 }
@@ -0,0 +1,116 @@
+package modem
+
+import "sync"
+
+// LoopEvent identifies an irregular condition the Loop handled on its own,
+// such as a response nobody was waiting for. The values are human readable,
+// so they can be logged or reported as is.
+type LoopEvent string
+
+const (
+	// EventURCDropped is reported when a URC was dropped because the URC
+	// channel was full
+	EventURCDropped LoopEvent = "URC dropped"
+	// EventURCSuppressed is reported when a URC was replaced by a later one
+	// of the same kind before its rate limit let it through, see
+	// ConfigBuilder.WithURCRateLimit
+	EventURCSuppressed LoopEvent = "URC suppressed"
+	// EventOrphanedResponse is reported for a final response that arrived
+	// with no command pending
+	EventOrphanedResponse LoopEvent = "orphaned final response"
+	// EventOrphanedData is reported for an intermediate response line that
+	// arrived with no command pending
+	EventOrphanedData LoopEvent = "orphaned data"
+	// EventLateResponse is reported when the final response of a timed out
+	// or aborted command was discarded
+	EventLateResponse LoopEvent = "late response discarded"
+	// EventLatePrompt is reported when the text entry prompt of a timed out
+	// or aborted command was left with ESC
+	EventLatePrompt LoopEvent = "late prompt left"
+	// EventConfigDrift is reported when a setting made during
+	// initialization was found changed and applied again, see CheckConfig.
+	// Unlike the other events, it is not reported from the Loop.
+	EventConfigDrift LoopEvent = "configuration drift"
+	// EventResync is reported when the Loop pinged the modem to pair
+	// commands and responses again
+	EventResync LoopEvent = "resync"
+)
+
+// eventMetrics are the counters the loop events are reported as.
+var eventMetrics = map[LoopEvent]string{
+	EventURCDropped:       MetricURCsDropped,
+	EventURCSuppressed:    MetricURCsSuppressed,
+	EventOrphanedResponse: MetricOrphanedResponses,
+	EventOrphanedData:     MetricOrphanedData,
+	EventLateResponse:     MetricLateResponses,
+	EventLatePrompt:       MetricLatePrompts,
+	EventResync:           MetricResyncs,
+	EventConfigDrift:      MetricConfigDrifts,
+}
+
+// Diagnostic is reported to the callback set with
+// ConfigBuilder.WithDiagnostics whenever a loop event occurs.
+type Diagnostic struct {
+	// Event is the condition that occurred
+	Event LoopEvent
+	// Token is the modem output that caused the event, if any
+	Token string
+	// Count is the number of times the event occurred so far, including
+	// this one
+	Count uint64
+}
+
+// diagnostics counts loop events and reports them to the metrics and the
+// configured callback, if any.
+type diagnostics struct {
+	report  func(Diagnostic)
+	metrics Metrics
+
+	mu     sync.Mutex
+	counts map[LoopEvent]uint64
+}
+
+func newDiagnostics(report func(Diagnostic), metrics Metrics) *diagnostics {
+	return &diagnostics{
+		report:  report,
+		metrics: metrics,
+		counts:  make(map[LoopEvent]uint64),
+	}
+}
+
+// record counts an occurrence of event caused by token.
+func (d *diagnostics) record(event LoopEvent, token string) {
+	d.mu.Lock()
+	d.counts[event]++
+	count := d.counts[event]
+	d.mu.Unlock()
+
+	d.metrics.AddCounter(eventMetrics[event], 1)
+	if d.report != nil {
+		d.report(Diagnostic{Event: event, Token: token, Count: count})
+	}
+}
+
+// count returns the number of occurrences of event.
+func (d *diagnostics) count(event LoopEvent) uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.counts[event]
+}
+
+// snapshot returns a copy of all counts.
+func (d *diagnostics) snapshot() map[LoopEvent]uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	counts := make(map[LoopEvent]uint64, len(d.counts))
+	for event, n := range d.counts {
+		counts[event] = n
+	}
+	return counts
+}
+
+// Diagnostics returns how often each loop event occurred since the modem
+// was created. Events that never occurred are omitted.
+func (m *Modem) Diagnostics() map[LoopEvent]uint64 {
+	return m.diag.snapshot()
+}
@@ -0,0 +1,146 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// setting is a modem setting made during initialization that CheckConfig
+// verifies.
+type setting struct {
+	// name identifies the setting in logs and results
+	name string
+	// query is the command reading the setting
+	query string
+	// drifted reports whether the response to query shows a changed setting
+	drifted func(resp string) bool
+	// apply is the command making the setting again
+	apply string
+}
+
+// settings returns the settings to verify, in the order they are checked.
+// Echo comes first, as an echo in the responses is the most disruptive.
+func (m *Modem) settings() []setting {
+	settings := []setting{
+		{
+			name:  "echo",
+			query: at.CmdAt,
+			drifted: func(resp string) bool {
+				return hasLine(resp, at.CmdAt)
+			},
+			apply: at.CmdEchoOff,
+		},
+		{
+			name:  "message format",
+			query: at.CmdMsgFormat,
+			drifted: func(resp string) bool {
+				return !hasParam(resp, at.MsgFormat, "1")
+			},
+			apply: at.CmdSetTextMode,
+		},
+		{
+			name:  "character set",
+			query: at.CmdCharset,
+			drifted: func(resp string) bool {
+				return !hasParam(resp, at.Charset, m.config.charset)
+			},
+			apply: at.CSCS(m.config.charset),
+		},
+	}
+	if cmd := m.config.newMessageMode.command(); cmd != "" {
+		settings = append(settings, setting{
+			name:  "new message indication",
+			query: at.CmdNewMsgInd,
+			drifted: func(resp string) bool {
+				return !hasParam(resp, at.NewMsgInd, strings.TrimPrefix(cmd, "AT+CNMI="))
+			},
+			apply: cmd,
+		})
+	}
+	return settings
+}
+
+// CheckConfig verifies the settings made during initialization (echo,
+// message format, character set and new message indication) and applies
+// the ones the modem lost again, which would otherwise break parsing until
+// the modem is initialized again. It returns the names of the drifted
+// settings. Each drift is logged and reported as EventConfigDrift.
+//
+// The Loop runs the check periodically if WithDriftCheck is set.
+func (m *Modem) CheckConfig(ctx context.Context) ([]string, error) {
+	// A message in PDU mode or at the text prompt is not a drift
+	release, err := m.acquireSubmission(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var drifted []string
+	defer func() {
+		if len(drifted) > 0 {
+			m.recordLifecycle(LifecycleConfigRestored, strings.Join(drifted, ", "))
+		}
+	}()
+	for _, s := range m.settings() {
+		resp, err := m.exec(ctx, s.query)
+		if err != nil {
+			return drifted, fmt.Errorf("query %s: %w", s.name, err)
+		}
+		if !s.drifted(resp) {
+			continue
+		}
+
+		m.config.logger.Warn("modem configuration drifted", "setting", s.name, "response", resp)
+		m.diag.record(EventConfigDrift, resp)
+		drifted = append(drifted, s.name)
+		if _, err := m.exec(ctx, s.apply); err != nil {
+			return drifted, fmt.Errorf("restore %s: %w", s.name, err)
+		}
+	}
+	return drifted, nil
+}
+
+// watchConfig runs CheckConfig every drift check interval until ctx is
+// done or stop is closed.
+func (m *Modem) watchConfig(ctx context.Context, stop <-chan struct{}) {
+	ticker := m.config.clock.NewTicker(m.config.driftCheck)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			if _, err := m.CheckConfig(ctx); err != nil {
+				m.config.logger.Warn("configuration drift check failed", "err", err)
+			}
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		}
+	}
+}
+
+// hasLine reports whether resp contains the given line.
+func hasLine(resp, line string) bool {
+	for l := range strings.Lines(resp) {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// hasParam reports whether resp contains a prefix line whose parameters
+// start with the comma separated values of want.
+func hasParam(resp, prefix, want string) bool {
+	params, ok := at.Params(resp, prefix)
+	if !ok {
+		return false
+	}
+	got, expected := at.SplitFields(params), at.SplitFields(want)
+	return len(got) >= len(expected) && slices.Equal(got[:len(expected)], expected)
+}
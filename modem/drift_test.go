@@ -0,0 +1,148 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestCheckConfig(t *testing.T) {
+	// newModem initializes a modem, setting the new message indication with
+	// cnmi unless empty
+	newModem := func(t *testing.T, ctrl *gomock.Controller, builder *modem.ConfigBuilder, cnmi string) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		sequence := NewMockSequence(mockTransport).
+			AT().
+			EchoOff().
+			VerboseErrors().
+			SimReady().
+			SMSTextMode().
+			Charset("GSM")
+		if cnmi != "" {
+			sequence.NewMessageIndication(cnmi)
+		}
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				sequence.Registration("1").Build(),
+			)...,
+		)
+
+		config, err := builder.WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	t.Run("Nothing to restore", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder(), "")
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT\r", "OK\r\n"},
+			Exchange{"AT+CMGF?\r", "+CMGF: 1\r\n\r\nOK\r\n"},
+			Exchange{"AT+CSCS?\r", "+CSCS: \"GSM\"\r\n\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		drifted, err := m.CheckConfig(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(drifted) != 0 {
+			t.Errorf("expected no drifted settings, got %v", drifted)
+		}
+		eof()
+	})
+
+	t.Run("Restores drifted settings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var events []modem.Diagnostic
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder().
+			WithNewMessageMode(modem.NewMessageDirect).
+			WithDiagnostics(func(d modem.Diagnostic) { events = append(events, d) }), "AT+CNMI=2,2,0,0,0")
+		defer m.Close()
+
+		// The modem came back from a reset with echo on and in PDU mode
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT\r", "AT\r\nOK\r\n"},
+			Exchange{"ATE0\r", "ATE0\r\nOK\r\n"},
+			Exchange{"AT+CMGF?\r", "+CMGF: 0\r\n\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+			Exchange{"AT+CSCS?\r", "+CSCS: \"GSM\"\r\n\r\nOK\r\n"},
+			Exchange{"AT+CNMI?\r", "+CNMI: 1,1,0,0,0\r\n\r\nOK\r\n"},
+			Exchange{"AT+CNMI=2,2,0,0,0\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		drifted, err := m.CheckConfig(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		eof()
+
+		want := []string{"echo", "message format", "new message indication"}
+		if !slices.Equal(drifted, want) {
+			t.Errorf("expected drifted %v, got %v", want, drifted)
+		}
+		if len(events) != len(want) || events[0].Event != modem.EventConfigDrift {
+			t.Errorf("expected %d drift events, got %v", len(want), events)
+		}
+	})
+
+	t.Run("Checks periodically from the Loop", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		clock := newFakeClock()
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder().
+			WithClock(clock).
+			WithMinSendInterval(0).
+			WithDriftCheck(time.Minute), "")
+		defer m.Close()
+
+		restored := make(chan struct{})
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT\r", "OK\r\n"},
+			Exchange{"AT+CMGF?\r", "+CMGF: 1\r\n\r\nOK\r\n"},
+			Exchange{"AT+CSCS?\r", "+CSCS: \"IRA\"\r\n\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Write([]byte("AT+CSCS=\"GSM\"\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(restored)
+			return len(p), nil
+		})
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		clock.WaitForWaiter()
+		clock.Advance(time.Minute)
+		<-restored
+		eof()
+	})
+}
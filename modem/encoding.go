@@ -0,0 +1,139 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+// Encoding selects how the text of a message is coded on the air
+// interface, see SendSMSEncoded.
+type Encoding int
+
+const (
+	// EncodingAuto sends the message like SendSMS does, in GSM 7-bit unless
+	// it contains characters outside of that alphabet
+	EncodingAuto Encoding = iota
+	// EncodingGSM7 sends the message in the GSM 7-bit default alphabet,
+	// 160 characters per message. Characters outside of it are rejected.
+	EncodingGSM7
+	// EncodingUCS2 sends the message as UCS-2 in PDU mode, 70 characters
+	// per message, which covers any script and emoji
+	EncodingUCS2
+)
+
+var encodingNames = map[Encoding]string{
+	EncodingAuto: "auto",
+	EncodingGSM7: "gsm7",
+	EncodingUCS2: "ucs2",
+}
+
+func (e Encoding) String() string {
+	if name, ok := encodingNames[e]; ok {
+		return name
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// MarshalText encodes the encoding by its name.
+func (e Encoding) MarshalText() ([]byte, error) {
+	if _, ok := encodingNames[e]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedEncoding, int(e))
+	}
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText decodes "auto", "gsm7" or "ucs2", so that API requests can
+// name the encoding. Other names fail with ErrUnsupportedEncoding.
+func (e *Encoding) UnmarshalText(text []byte) error {
+	for encoding, name := range encodingNames {
+		if name == string(text) {
+			*e = encoding
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, text)
+}
+
+// SendSMSEncoded sends a text message like SendSMS, coded as enc instead
+// of the automatic choice, for cases where that picks the wrong trade-off
+// between length and fidelity.
+//
+// EncodingGSM7 fails with ErrUnsupportedEncoding if the message contains
+// characters outside of the GSM 7-bit alphabet, rather than have the modem
+// replace them. EncodingUCS2 switches to PDU mode for the duration of the
+// send and splits long messages into the parts of a concatenated message.
+func (m *Modem) SendSMSEncoded(ctx context.Context, recipient, message string, enc Encoding) error {
+	switch enc {
+	case EncodingAuto:
+		return m.SendSMS(ctx, recipient, message)
+	case EncodingGSM7:
+		if nonGSM := pdu.Measure(message).NonGSM; len(nonGSM) > 0 {
+			return fmt.Errorf("%w: %q not in the GSM 7-bit alphabet", ErrUnsupportedEncoding, nonGSM[0])
+		}
+		return m.SendSMS(ctx, recipient, message)
+	case EncodingUCS2:
+		recipient, message, err := m.prepareText(recipient, message)
+		if err != nil {
+			return err
+		}
+		return m.sendEncoded(ctx, recipient, message, EncodingUCS2)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedEncoding, enc)
+	}
+}
+
+// textEncoding returns the encoding a message is sent in when the modem's
+// text mode would not pass it unchanged: GSM 7-bit if it fits the
+// alphabet, UCS-2 otherwise.
+func textEncoding(message string) Encoding {
+	if len(pdu.Measure(message).NonGSM) > 0 {
+		return EncodingUCS2
+	}
+	return EncodingGSM7
+}
+
+// sendEncoded submits a prepared text message in PDU mode, coded as enc.
+func (m *Modem) sendEncoded(ctx context.Context, recipient, message string, enc Encoding) error {
+	tpdus, err := m.encodeMessage(recipient, message, enc)
+	if err != nil {
+		return err
+	}
+	return m.submit(ctx, func() error {
+		return m.sendPDU(ctx, tpdus)
+	})
+}
+
+// maxParts is the number of parts a concatenated message can have, the
+// concatenation header counts them in an octet.
+const maxParts = 255
+
+// encodeMessage returns the TPDUs sending message as GSM 7-bit or UCS-2.
+// The parts of a long message are numbered with the next concatenation
+// reference.
+func (m *Modem) encodeMessage(recipient, message string, enc Encoding) ([][]byte, error) {
+	parts, dcs := pdu.SplitUCS2(message), pdu.DCSUCS2
+	if enc == EncodingGSM7 {
+		parts, dcs = pdu.SplitGSM7(message), pdu.DCS7Bit
+	}
+	if len(parts) > maxParts {
+		return nil, fmt.Errorf("%w: %d parts", ErrMessageTooLong, len(parts))
+	}
+	// The parts leave room for the header with an 8-bit reference only
+	ref := uint16(uint8(m.concatRef.Add(1)))
+
+	tpdus := make([][]byte, 0, len(parts))
+	for i, part := range parts {
+		submit := pdu.Submit{Recipient: recipient, DCS: dcs, UserData: part}
+		if len(parts) > 1 {
+			submit.UDH = pdu.Concat{Ref: ref, Total: len(parts), Seq: i + 1}.Encode()
+		}
+		tpdu, err := submit.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("encode PDU: %w", err)
+		}
+		tpdus = append(tpdus, tpdu)
+	}
+	return tpdus, nil
+}
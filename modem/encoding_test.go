@@ -0,0 +1,182 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestSendSMSEncoded(t *testing.T) {
+	newLoopingModem := func(t *testing.T, ctrl *gomock.Controller) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	t.Run("UCS2 in PDU mode", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=20\r", "> "},
+			Exchange{"0001000A912143658709000808039303B503B903AC\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendSMSEncoded(context.Background(), "+1234567890", "Γειά", modem.EncodingUCS2)
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Auto detects characters outside of GSM 7-bit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 4\r\nOK\r\n"},
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=20\r", "> "},
+			Exchange{"0001000A912143658709000808039303B503B903AC\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		ctx := context.Background()
+		if err := m.SendSMSEncoded(ctx, "+1234567890", "Hello", modem.EncodingAuto); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := m.SendSMS(ctx, "+1234567890", "Γειά"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		eof()
+	})
+
+	t.Run("GSM text not coded alike in ASCII is sent as 7-bit PDU", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		defer m.Close()
+
+		// é and £ are the septets 0x05 and 0x01
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=19\r", "> "},
+			Exchange{"0001000A912143658709000007E3B0B9000AD400\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendSMS(context.Background(), "+1234567890", "café £5")
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Long UCS2 message is concatenated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		defer m.Close()
+
+		// 67 characters fit next to the concatenation header
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=152\r", "> "},
+			Exchange{"0041000A91214365870900088C050003010201" + strings.Repeat("0416", 67) + "\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGS=26\r", "> "},
+			Exchange{"0041000A91214365870900080E050003010202" + strings.Repeat("0416", 4) + "\x1a\r", "+CMGS: 6\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendSMSEncoded(context.Background(), "+1234567890", strings.Repeat("Ж", 71), modem.EncodingUCS2)
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("GSM7 rejects other characters before touching the modem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		err := m.SendSMSEncoded(context.Background(), "+1234567890", "20:00–21:00", modem.EncodingGSM7)
+		if !errors.Is(err, modem.ErrUnsupportedEncoding) {
+			t.Errorf("expected ErrUnsupportedEncoding, got: %v", err)
+		}
+	})
+
+	t.Run("UCS2 message over 255 parts is rejected before touching the modem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl)
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		err := m.SendSMSEncoded(context.Background(), "+1234567890", strings.Repeat("Ж", 255*67+1), modem.EncodingUCS2)
+		if !errors.Is(err, modem.ErrMessageTooLong) {
+			t.Errorf("expected ErrMessageTooLong, got: %v", err)
+		}
+	})
+
+	t.Run("Encoding names", func(t *testing.T) {
+		var enc modem.Encoding
+		if err := enc.UnmarshalText([]byte("ucs2")); err != nil || enc != modem.EncodingUCS2 {
+			t.Errorf("expected EncodingUCS2, got %v (err: %v)", enc, err)
+		}
+		if err := enc.UnmarshalText([]byte("utf8")); !errors.Is(err, modem.ErrUnsupportedEncoding) {
+			t.Errorf("expected ErrUnsupportedEncoding, got: %v", err)
+		}
+	})
+}
@@ -1,6 +1,10 @@
 package modem
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
 
 var (
 	// ErrNoDialer is returned when a Modem is constructed without a Dialer.
@@ -27,6 +31,12 @@ var (
 	// the user for a PIN) and retry initialization.
 	ErrSIMPinRequired = errors.New("SIM PIN required")
 
+	// ErrSIMPinAttemptsLow is returned when the configured PIN is not
+	// entered because only one attempt is left. A wrong PIN would then
+	// block the SIM until it is unlocked with its PUK, so the PIN has to be
+	// verified and entered manually.
+	ErrSIMPinAttemptsLow = errors.New("SIM PIN attempts low")
+
 	// ErrNilContext is returned when a nil context is passed to a function
 	// that requires a valid context.
 	//
@@ -50,8 +60,91 @@ var (
 	// specific failure reason.
 	ErrPortOpenFail = errors.New("failed to open serial port")
 
+	// ErrNotRegistered is reported when the modem did not register to the
+	// network within the configured registration wait.
+	ErrNotRegistered = errors.New("not registered to network")
+
+	// ErrUnsupportedCharset is returned by ConfigBuilder.Build when the
+	// configured character set is not one of GSM, IRA or UCS2.
+	ErrUnsupportedCharset = errors.New("unsupported character set")
+
+	// ErrUnsupportedEncoding is returned for a message encoding other than
+	// the Encoding constants, or a message that cannot be sent in the
+	// requested encoding, such as Greek text in GSM 7-bit.
+	ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+	// ErrNotSupported is returned for a query the modem answered none of
+	// the known vendor commands of, such as data usage counters on a
+	// module that does not track them.
+	ErrNotSupported = errors.New("not supported by modem")
+
+	// ErrMessageTooLong is returned for a message that takes more parts
+	// than the 255 a concatenated message can have.
+	ErrMessageTooLong = errors.New("message too long")
+
+	// ErrCharsetMismatch is returned during initialization when the modem
+	// reports a different character set than the one that was selected.
+	ErrCharsetMismatch = errors.New("character set mismatch")
+
+	// ErrInvalidPollConfig is returned by ConfigBuilder.Build when a
+	// PollConfig has negative values or an interval longer than its timeout.
+	ErrInvalidPollConfig = errors.New("invalid poll config")
+
+	// ErrInvalidRecipient is returned when an SMS recipient is not a phone
+	// number made of dialling digits with an optional leading "+". Anything
+	// else could end the quoted AT+CMGS parameter and inject commands.
+	ErrInvalidRecipient = errors.New("invalid recipient")
+
+	// ErrInvalidMessage is returned when a text message contains control
+	// characters that would end or abort the text entry, such as Ctrl-Z or
+	// ESC.
+	ErrInvalidMessage = errors.New("invalid message")
+
+	// ErrAborted is returned to a command that was cancelled with
+	// Modem.Abort before the modem answered it.
+	ErrAborted = errors.New("command aborted")
+
+	// ErrLearning is returned by Modem.LearnURCs when another learning is
+	// still running.
+	ErrLearning = errors.New("URC learning already running")
+
+	// ErrKeepaliveTimeout is returned by Loop when the modem did not answer
+	// a keepalive ping, see ConfigBuilder.WithKeepalive. The transport is
+	// most likely dead and needs to be reconnected.
+	ErrKeepaliveTimeout = errors.New("modem did not answer keepalive ping")
+
 	// ErrLoopRunning is returned when Loop() is called while the modem loop is
 	// already running. This is used to prohibit concurrent execution of multiple
 	// loops, which could cause race conditions and undefined behavior.
 	ErrLoopRunning = errors.New("modem loop already running")
 )
+
+// PanicError is returned when a panic is recovered in one of the modem's
+// internal goroutines. It keeps the panic value and the stack trace of the
+// panicking goroutine, so that the failure can be reported instead of
+// crashing the process.
+type PanicError struct {
+	// Goroutine names the internal goroutine that panicked ("loop", "reader")
+	Goroutine string
+	// Value is the value passed to panic
+	Value any
+	// Stack is the stack trace captured at recovery
+	Stack []byte
+}
+
+// newPanicError wraps a recovered panic value. It must be called from the
+// deferred function that recovered, so that the captured stack still
+// contains the panicking frames.
+func newPanicError(goroutine string, value any) *PanicError {
+	return &PanicError{Goroutine: goroutine, Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic in modem %s: %v", e.Goroutine, e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,14 @@
+package modem
+
+import "context"
+
+// Exec and ExecTelemetry queue a command on the message and the telemetry
+// lane of the Loop without taking the submission slot, so tests can fill
+// both lanes at once.
+func (m *Modem) Exec(ctx context.Context, cmd string) (string, error) {
+	return m.exec(ctx, cmd)
+}
+
+func (m *Modem) ExecTelemetry(ctx context.Context, cmd string) (string, error) {
+	return m.execTelemetry(ctx, cmd)
+}
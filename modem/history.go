@@ -0,0 +1,94 @@
+package modem
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultHistorySize is the number of lifecycle events kept by default.
+const defaultHistorySize = 64
+
+// Lifecycle is a change in the life of a modem, kept in its history.
+type Lifecycle string
+
+const (
+	// LifecycleInitialized is recorded when New initialized the modem,
+	// the detail tells whether it registered to the network
+	LifecycleInitialized Lifecycle = "initialized"
+	// LifecycleLoopStarted is recorded when Loop starts
+	LifecycleLoopStarted Lifecycle = "loop started"
+	// LifecycleLoopStopped is recorded when Loop returns, with the error
+	// that ended it as detail, e.g. a lost transport
+	LifecycleLoopStopped Lifecycle = "loop stopped"
+	// LifecycleClosed is recorded when the modem is closed
+	LifecycleClosed Lifecycle = "closed"
+	// LifecyclePaused and LifecycleResumed are recorded when sending is
+	// paused and resumed
+	LifecyclePaused  Lifecycle = "paused"
+	LifecycleResumed Lifecycle = "resumed"
+	// LifecycleConfigRestored is recorded when CheckConfig found drifted
+	// settings, usually after the modem reset itself. The detail lists
+	// the settings.
+	LifecycleConfigRestored Lifecycle = "configuration restored"
+	// LifecycleSIMChanged is recorded when SIMStatus reports another state
+	// than before, e.g. a removed SIM
+	LifecycleSIMChanged Lifecycle = "SIM state changed"
+)
+
+// HistoryEntry is an event in the history of a modem.
+type HistoryEntry struct {
+	Time  time.Time `json:"time"`
+	Event Lifecycle `json:"event"`
+	// Detail describes the event, if there is more to it
+	Detail string `json:"detail,omitempty"`
+}
+
+// history keeps the latest lifecycle events in a ring buffer.
+type history struct {
+	mu      sync.Mutex
+	entries []HistoryEntry
+	// next is the position the next entry is written to
+	next int
+	// full is set once the ring wrapped around
+	full bool
+}
+
+func newHistory(size int) *history {
+	return &history{entries: make([]HistoryEntry, max(size, 0))}
+}
+
+// add appends an entry, replacing the oldest one if the ring is full.
+func (h *history) add(entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.entries) == 0 {
+		return
+	}
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the entries, oldest first.
+func (h *history) snapshot() []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]HistoryEntry(nil), h.entries[:h.next]...)
+	}
+	return append(append([]HistoryEntry(nil), h.entries[h.next:]...), h.entries[:h.next]...)
+}
+
+// recordLifecycle adds an event to the history of the modem.
+func (m *Modem) recordLifecycle(event Lifecycle, detail string) {
+	m.history.add(HistoryEntry{Time: m.config.clock.Now(), Event: event, Detail: detail})
+}
+
+// History returns the latest lifecycle events of the modem, oldest first,
+// to see at a glance what it has been doing since it was created. The
+// number of events kept is set with ConfigBuilder.WithHistorySize.
+func (m *Modem) History() []HistoryEntry {
+	return m.history.snapshot()
+}
@@ -0,0 +1,139 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestHistory(t *testing.T) {
+	newModem := func(t *testing.T, ctrl *gomock.Controller, builder *modem.ConfigBuilder) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := builder.WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	events := func(history []modem.HistoryEntry) []modem.Lifecycle {
+		var events []modem.Lifecycle
+		for _, entry := range history {
+			events = append(events, entry.Event)
+		}
+		return events
+	}
+
+	t.Run("Records lifecycle events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder())
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CPIN?\r", "+CPIN: READY\r\n\r\nOK\r\n"},
+			Exchange{"AT+CPIN?\r", "+CME ERROR: SIM not inserted\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			m.Loop(ctx)
+		}()
+
+		for range 2 {
+			if _, err := m.SIMStatus(ctx); err != nil {
+				t.Fatalf("unexpected error from SIMStatus(): %v", err)
+			}
+		}
+		eof()
+		m.Pause()
+		m.Resume()
+		cancel()
+		<-done
+		m.Close()
+
+		history := m.History()
+		expected := []modem.Lifecycle{
+			modem.LifecycleInitialized,
+			modem.LifecycleLoopStarted,
+			modem.LifecycleSIMChanged,
+			modem.LifecyclePaused,
+			modem.LifecycleResumed,
+			modem.LifecycleLoopStopped,
+			modem.LifecycleClosed,
+		}
+		if got := events(history); !slices.Equal(got, expected) {
+			t.Fatalf("expected events %v, got %v", expected, got)
+		}
+		if history[0].Detail != "registered" {
+			t.Errorf("expected initialization detail %q, got %q", "registered", history[0].Detail)
+		}
+		if history[2].Detail != "ready -> not inserted" {
+			t.Errorf("expected SIM change detail %q, got %q", "ready -> not inserted", history[2].Detail)
+		}
+		for i := 1; i < len(history); i++ {
+			if history[i].Time.Before(history[i-1].Time) {
+				t.Errorf("entry %d is older than its predecessor", i)
+			}
+		}
+	})
+
+	t.Run("Keeps the latest events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder().WithHistorySize(3))
+		mockTransport.EXPECT().Close().Return(nil)
+
+		m.Pause()
+		m.Resume()
+		m.Pause()
+		m.Close()
+
+		expected := []modem.Lifecycle{
+			modem.LifecycleResumed,
+			modem.LifecyclePaused,
+			modem.LifecycleClosed,
+		}
+		if got := events(m.History()); !slices.Equal(got, expected) {
+			t.Errorf("expected events %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Keeps nothing with zero size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl, modem.NewConfigBuilder().WithHistorySize(0))
+		mockTransport.EXPECT().Close().Return(nil)
+
+		m.Pause()
+		m.Close()
+
+		if history := m.History(); len(history) != 0 {
+			t.Errorf("expected no events, got %v", history)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package modem
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// URCCandidate is a line prefix seen while learning URCs, see
+// Modem.LearnURCs.
+type URCCandidate struct {
+	// Prefix is the line up to and including its colon, or the whole line
+	// if it has no parameters, e.g. "+QIND:" or "RDY"
+	Prefix string `json:"prefix"`
+	// Count is the number of lines seen with the prefix
+	Count int `json:"count"`
+	// Example is the first line seen with the prefix
+	Example string `json:"example"`
+}
+
+// SuggestClassifier returns a classifier taking the candidates for URCs.
+// The candidates should be reviewed first, learning can also pick up the
+// late answers of timed out commands.
+func SuggestClassifier(candidates []URCCandidate) at.PrefixClassifier {
+	prefixes := make(map[string]at.ResponseType, len(candidates))
+	for _, candidate := range candidates {
+		prefixes[candidate.Prefix] = at.TypeURC
+	}
+	return at.PrefixClassifier{Prefixes: prefixes}
+}
+
+// urcLearner collects the lines the classifier did not recognize.
+type urcLearner struct {
+	mu         sync.Mutex
+	candidates map[string]*URCCandidate
+}
+
+// record counts line under its prefix.
+func (l *urcLearner) record(line string) {
+	prefix := urcPrefix(line)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if candidate, ok := l.candidates[prefix]; ok {
+		candidate.Count++
+		return
+	}
+	l.candidates[prefix] = &URCCandidate{Prefix: prefix, Count: 1, Example: line}
+}
+
+// result returns the candidates, most frequent first.
+func (l *urcLearner) result() []URCCandidate {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	candidates := make([]URCCandidate, 0, len(l.candidates))
+	for _, candidate := range l.candidates {
+		candidates = append(candidates, *candidate)
+	}
+	slices.SortFunc(candidates, func(a, b URCCandidate) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Prefix, b.Prefix))
+	})
+	return candidates
+}
+
+// urcPrefix returns the prefix of line a classifier would match, the name
+// before the colon or the whole line for bare codes like "RDY".
+func urcPrefix(line string) string {
+	name, _, found := strings.Cut(line, ":")
+	if !found || name == "" || strings.ContainsAny(name, " ,\"") {
+		return line
+	}
+	return name + ":"
+}
+
+// LearnURCs records the lines the classifier does not recognize for the
+// duration d and returns their prefixes, most frequent first. These are
+// lines arriving while no command is pending, usually the vendor URCs
+// and startup banners of a modem model the classifier does not know.
+// SuggestClassifier turns them into a configuration for WithClassifier.
+//
+// The Loop must be running. Only one learning may run at a time, else
+// ErrLearning is returned. If ctx is cancelled, the lines recorded so far
+// are returned with the context error.
+func (m *Modem) LearnURCs(ctx context.Context, d time.Duration) ([]URCCandidate, error) {
+	learner := &urcLearner{candidates: make(map[string]*URCCandidate)}
+	if !m.learner.CompareAndSwap(nil, learner) {
+		return nil, ErrLearning
+	}
+	defer m.learner.Store(nil)
+
+	timer := m.config.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return learner.result(), nil
+	case <-ctx.Done():
+		return learner.result(), ctx.Err()
+	case <-m.done:
+		return learner.result(), fmt.Errorf("learn URCs: %w", ErrAlreadyClosed)
+	}
+}
@@ -0,0 +1,100 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/at"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestLearnURCs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransport := modem.NewMockTransport(ctrl)
+	mockDialer := modem.NewMockDialer(ctrl)
+
+	gomock.InOrder(
+		slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+		)...,
+	)
+
+	clock := newFakeClock()
+	config, err := modem.NewConfigBuilder().
+		WithDialer(mockDialer).
+		WithClock(clock).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error from Build(): %v", err)
+	}
+
+	ctx := context.Background()
+	m, err := modem.New(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to create modem: %v", err)
+	}
+	defer m.Close()
+
+	// Vendor URCs the default classifier takes for data, followed by a known
+	// URC telling they were all read
+	feed := make(chan struct{})
+	mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		<-feed
+		return copy(p, "+QIND: \"csq\",20,99\r\n+QIND: SMS DONE\r\nRDY\r\n^RSSI: 17\r\n+QIND: PB DONE\r\n+CMTI: \"SM\",1\r\n"), nil
+	})
+	eof := expectExchanges(mockTransport)
+	mockTransport.EXPECT().Close().Return(nil)
+
+	go m.Loop(ctx)
+
+	type result struct {
+		candidates []modem.URCCandidate
+		err        error
+	}
+	learned := make(chan result, 1)
+	go func() {
+		candidates, err := m.LearnURCs(ctx, time.Minute)
+		learned <- result{candidates, err}
+	}()
+
+	clock.WaitForWaiter()
+	if _, err := m.LearnURCs(ctx, time.Minute); !errors.Is(err, modem.ErrLearning) {
+		t.Errorf("expected ErrLearning for a second learning, got %v", err)
+	}
+
+	close(feed)
+	select {
+	case <-m.URC():
+	case <-time.After(time.Second):
+		t.Fatal("expected URC to be received within timeout")
+	}
+	clock.Advance(time.Minute)
+
+	res := <-learned
+	if res.err != nil {
+		t.Fatalf("unexpected error: %v", res.err)
+	}
+	expected := []modem.URCCandidate{
+		{Prefix: "+QIND:", Count: 3, Example: "+QIND: \"csq\",20,99"},
+		{Prefix: "RDY", Count: 1, Example: "RDY"},
+		{Prefix: "^RSSI:", Count: 1, Example: "^RSSI: 17"},
+	}
+	if !slices.Equal(res.candidates, expected) {
+		t.Errorf("expected %+v, got %+v", expected, res.candidates)
+	}
+
+	classifier := modem.SuggestClassifier(res.candidates)
+	if got := classifier.Classify("^RSSI: 12"); got != at.TypeURC {
+		t.Errorf("expected suggested classifier to take ^RSSI for a URC, got %v", got)
+	}
+	eof()
+}
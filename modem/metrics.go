@@ -0,0 +1,71 @@
+package modem
+
+// Metrics receives the telemetry of a Modem. It is deliberately small, so
+// that embedding applications can forward it to Prometheus, statsd or any
+// other backend with a thin adapter, without this package importing one.
+//
+// Names are the Metric* constants. Implementations must be safe for
+// concurrent use, the methods are called from the Loop as well as from the
+// goroutines sending messages.
+type Metrics interface {
+	// AddCounter increases the counter name by delta.
+	AddCounter(name string, delta float64)
+	// SetGauge sets the gauge name to value.
+	SetGauge(name string, value float64)
+	// ObserveHistogram records value in the histogram name.
+	ObserveHistogram(name string, value float64)
+}
+
+const (
+	// MetricSMSSent counts messages accepted by the network
+	MetricSMSSent = "modem_sms_sent_total"
+	// MetricSMSFailed counts messages submitted to the modem that could not
+	// be sent. Messages rejected by validation are not counted.
+	MetricSMSFailed = "modem_sms_failed_total"
+	// MetricCommandDuration observes the seconds from writing an AT command
+	// to its final response or prompt
+	MetricCommandDuration = "modem_command_duration_seconds"
+	// MetricSubmitDuration observes the seconds from submitting a message
+	// body to the network accepting the message
+	MetricSubmitDuration = "modem_sms_submit_duration_seconds"
+	// MetricOrphanedResponses counts final responses with no command pending
+	MetricOrphanedResponses = "modem_orphaned_responses_total"
+	// MetricOrphanedData counts intermediate response lines with no command
+	// pending
+	MetricOrphanedData = "modem_orphaned_data_total"
+	// MetricLateResponses counts discarded final responses of timed out or
+	// aborted commands
+	MetricLateResponses = "modem_late_responses_total"
+	// MetricLatePrompts counts text entry prompts of timed out or aborted
+	// commands that were left
+	MetricLatePrompts = "modem_late_prompts_total"
+	// MetricConfigDrifts counts settings found changed and applied again
+	MetricConfigDrifts = "modem_config_drifts_total"
+	// MetricResyncs counts resync pings after aborted commands or orphans
+	MetricResyncs = "modem_resyncs_total"
+	// MetricURCsDropped counts URCs dropped because the URC channel was full
+	MetricURCsDropped = "modem_urcs_dropped_total"
+	// MetricURCsSuppressed counts URCs coalesced away by a rate limit
+	MetricURCsSuppressed = "modem_urcs_suppressed_total"
+	// MetricReady is 1 if the modem was registered when initialization
+	// finished, 0 otherwise
+	MetricReady = "modem_ready"
+	// MetricDataSent is the number of packet data bytes sent as of the
+	// latest DataUsage query
+	MetricDataSent = "modem_data_sent_bytes"
+	// MetricDataReceived is the number of packet data bytes received as of
+	// the latest DataUsage query
+	MetricDataReceived = "modem_data_received_bytes"
+	// MetricPaused is 1 while sending is paused, 0 otherwise
+	MetricPaused = "modem_paused"
+)
+
+// NopMetrics discards all telemetry. It is used when no Metrics are
+// configured.
+type NopMetrics struct{}
+
+func (NopMetrics) AddCounter(string, float64) {}
+
+func (NopMetrics) SetGauge(string, float64) {}
+
+func (NopMetrics) ObserveHistogram(string, float64) {}
@@ -0,0 +1,118 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+// recordingMetrics keeps the last value of every counter and gauge and the
+// number of observations of every histogram.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *recordingMetrics) AddCounter(name string, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] += delta
+}
+
+func (r *recordingMetrics) SetGauge(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name] = value
+}
+
+func (r *recordingMetrics) ObserveHistogram(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[name]++
+}
+
+func (r *recordingMetrics) get(name string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[name]
+}
+
+// lookup returns the value of name and whether it was reported at all.
+func (r *recordingMetrics) lookup(name string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[name]
+	return value, ok
+}
+
+func TestMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransport := modem.NewMockTransport(ctrl)
+	mockDialer := modem.NewMockDialer(ctrl)
+
+	gomock.InOrder(
+		slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+		)...,
+	)
+
+	metrics := &recordingMetrics{values: map[string]float64{}}
+	config, err := modem.NewConfigBuilder().
+		WithDialer(mockDialer).
+		WithMinSendInterval(0).
+		WithMetrics(metrics).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error from Build(): %v", err)
+	}
+
+	ctx := context.Background()
+	m, err := modem.New(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to create modem: %v", err)
+	}
+	defer m.Close()
+
+	if got := metrics.get(modem.MetricReady); got != 1 {
+		t.Errorf("expected %s 1 after init, got %v", modem.MetricReady, got)
+	}
+
+	eof := expectExchanges(mockTransport,
+		Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+		Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+		Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+		Exchange{"Hello\x1a\r", "+CMS ERROR: 500\r\n"},
+	)
+	mockTransport.EXPECT().Close().Return(nil)
+
+	go m.Loop(ctx)
+
+	if err := m.SendSMS(ctx, "+1234567890", "Hello"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := m.SendSMS(ctx, "+1234567890", "Hello"); err == nil {
+		t.Error("expected error from rejected message")
+	}
+	eof()
+
+	expected := map[string]float64{
+		modem.MetricSMSSent:         1,
+		modem.MetricSMSFailed:       1,
+		modem.MetricCommandDuration: 4,
+		modem.MetricSubmitDuration:  1,
+	}
+	for name, want := range expected {
+		if got := metrics.get(name); got != want {
+			t.Errorf("expected %s %v, got %v", name, want, got)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package modem_test
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
 	gomock "go.uber.org/mock/gomock"
 	"i4.energy/across/smsgw/modem"
 )
@@ -29,6 +37,20 @@ func (b *MockSequenceBuilder) AT() *MockSequenceBuilder {
 	return b
 }
 
+// ProfileReset answers a profile reset command (ATZ, AT&F). The modem still
+// echoes the command, as the restored profile has echo enabled.
+func (b *MockSequenceBuilder) ProfileReset(cmd string) *MockSequenceBuilder {
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte(cmd+"\r")).Return(len(cmd)+1, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := cmd + "\r\nOK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
 func (b *MockSequenceBuilder) EchoOff() *MockSequenceBuilder {
 	b.calls = append(b.calls,
 		b.transport.EXPECT().Write([]byte("ATE0\r")).Return(6, nil),
@@ -65,6 +87,33 @@ func (b *MockSequenceBuilder) SimPinRequired() *MockSequenceBuilder {
 	return b
 }
 
+// PINAttempts answers the Quectel PIN counter query with the number of
+// PIN attempts left.
+func (b *MockSequenceBuilder) PINAttempts(n int) *MockSequenceBuilder {
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte("AT+QPINC=\"SC\"\r")).Return(14, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := fmt.Sprintf("+QPINC: \"SC\",%d,10\r\nOK\r\n", n)
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
+func (b *MockSequenceBuilder) EnterPIN(pin string) *MockSequenceBuilder {
+	cmd := `AT+CPIN="` + pin + `"` + "\r"
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte(cmd)).Return(len(cmd), nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := "OK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
 func (b *MockSequenceBuilder) SimReady() *MockSequenceBuilder {
 	b.calls = append(b.calls,
 		b.transport.EXPECT().Write([]byte("AT+CPIN?\r")).Return(9, nil),
@@ -89,6 +138,66 @@ func (b *MockSequenceBuilder) SMSTextMode() *MockSequenceBuilder {
 	return b
 }
 
+// Charset selects the given character set and confirms it on query.
+func (b *MockSequenceBuilder) Charset(charset string) *MockSequenceBuilder {
+	selectCmd := `AT+CSCS="` + charset + `"` + "\r"
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte(selectCmd)).Return(len(selectCmd), nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := "OK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+		b.transport.EXPECT().Write([]byte("AT+CSCS?\r")).Return(9, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := `+CSCS: "` + charset + `"` + "\r\nOK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
+// NewMessageIndication expects the given AT+CNMI command and accepts it.
+func (b *MockSequenceBuilder) NewMessageIndication(cmd string) *MockSequenceBuilder {
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte(cmd+"\r")).Return(len(cmd)+1, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := "OK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
+// SaveProfile answers saving the profile to NVRAM (AT&W).
+func (b *MockSequenceBuilder) SaveProfile() *MockSequenceBuilder {
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte("AT&W\r")).Return(5, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := "OK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
+// Registration answers a network registration query with the given <stat>
+// value ("1" home, "2" searching, "5" roaming).
+func (b *MockSequenceBuilder) Registration(stat string) *MockSequenceBuilder {
+	b.calls = append(b.calls,
+		b.transport.EXPECT().Write([]byte("AT+CREG?\r")).Return(9, nil),
+		b.transport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			resp := "+CREG: 0," + stat + "\r\nOK\r\n"
+			copy(p, resp)
+			return len(resp), nil
+		}),
+	)
+	return b
+}
+
 func (b *MockSequenceBuilder) Build() []any {
 	return b.calls
 }
@@ -102,5 +211,168 @@ func initMockCalls(mockTransport *modem.MockTransport) []any {
 		VerboseErrors().
 		SimReady().
 		SMSTextMode().
+		Charset("GSM").
+		Registration("1").
 		Build()
 }
+
+// Exchange is a single command written by the Loop together with the raw
+// bytes the modem answers with. An empty Response means no answer is read.
+type Exchange struct {
+	Command  string
+	Response string
+}
+
+// expectExchanges registers the given exchanges for a running Loop. Each
+// response Read blocks until its command has been written, mimicking real
+// hardware which only answers after receiving a command. The returned
+// function waits for the Loop to issue its final Read and releases it, which
+// reports io.EOF and stops the Loop.
+func expectExchanges(mockTransport *modem.MockTransport, exchanges ...Exchange) (eof func()) {
+	for _, ex := range exchanges {
+		written := make(chan struct{})
+		mockTransport.EXPECT().Write([]byte(ex.Command)).DoAndReturn(func(p []byte) (int, error) {
+			close(written)
+			return len(p), nil
+		})
+		if ex.Response == "" {
+			continue
+		}
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-written
+			return copy(p, ex.Response), nil
+		})
+	}
+
+	reading := make(chan struct{})
+	allowEOF := make(chan struct{})
+	mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		close(reading)
+		<-allowEOF
+		return 0, io.EOF
+	})
+	return func() {
+		<-reading
+		close(allowEOF)
+	}
+}
+
+// serialTransport adds the read timeout and input buffer control of a serial
+// port to a mock transport and records how they are used.
+type serialTransport struct {
+	*modem.MockTransport
+	calls []string
+}
+
+func (t *serialTransport) SetReadTimeout(d time.Duration) error {
+	t.calls = append(t.calls, fmt.Sprintf("SetReadTimeout(%s)", d))
+	return nil
+}
+
+func (t *serialTransport) ResetInputBuffer() error {
+	t.calls = append(t.calls, "ResetInputBuffer()")
+	return nil
+}
+
+// scriptedTransport answers every command written to it like a modem
+// would, with the response from answers or "OK". Unlike the mock it can
+// serve any number of commands, e.g. in benchmarks.
+type scriptedTransport struct {
+	answers   map[string]string
+	responses chan string
+	closed    chan struct{}
+	pending   string
+}
+
+func newScriptedTransport(answers map[string]string) *scriptedTransport {
+	return &scriptedTransport{
+		answers:   answers,
+		responses: make(chan string, 16),
+		closed:    make(chan struct{}),
+	}
+}
+
+func (t *scriptedTransport) Write(p []byte) (int, error) {
+	resp, ok := t.answers[strings.TrimSuffix(string(p), "\r")]
+	if !ok {
+		resp = "OK\r\n"
+	}
+	t.responses <- resp
+	return len(p), nil
+}
+
+func (t *scriptedTransport) Read(p []byte) (int, error) {
+	if t.pending == "" {
+		select {
+		case t.pending = <-t.responses:
+		case <-t.closed:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+	return n, nil
+}
+
+func (t *scriptedTransport) Close() error {
+	close(t.closed)
+	return nil
+}
+
+// scriptedDialer dials a scriptedTransport.
+type scriptedDialer struct {
+	transport *scriptedTransport
+}
+
+func (d scriptedDialer) Dial(context.Context) (modem.Transport, error) {
+	return d.transport, nil
+}
+
+// logLines passes every log record written to it on, so that tests can
+// wait for a log message instead of sleeping.
+type logLines chan string
+
+func newLogLines() logLines {
+	return make(logLines, 64)
+}
+
+func (l logLines) Write(p []byte) (int, error) {
+	l <- string(p)
+	return len(p), nil
+}
+
+// waitFor receives log records until one contains msg.
+func (l logLines) waitFor(t *testing.T, msg string) {
+	t.Helper()
+	for {
+		select {
+		case line := <-l:
+			if strings.Contains(line, msg) {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected log message %q within timeout", msg)
+		}
+	}
+}
+
+// queueSignal is a context that reports when the call using it is queued:
+// the modem first asks for Done when a message waits for its turn or a
+// command waits for the Loop to take it. It carries a deadline, so the
+// modem uses it unchanged.
+type queueSignal struct {
+	context.Context
+	once   sync.Once
+	queued chan struct{}
+}
+
+func newQueueSignal(t *testing.T) *queueSignal {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	t.Cleanup(cancel)
+	return &queueSignal{Context: ctx, queued: make(chan struct{})}
+}
+
+func (s *queueSignal) Done() <-chan struct{} {
+	s.once.Do(func() { close(s.queued) })
+	return s.Context.Done()
+}
@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.bug.st/serial"
 	"i4.energy/across/smsgw/at"
 )
 
@@ -18,22 +21,62 @@ import (
 type Modem struct {
 	// transport provides the physical connection to the modem (serial, TCP, etc.)
 	transport Transport
-	// config contains the modem configuration settings
+	// config is the snapshot of the configuration taken by New. It is never
+	// modified afterwards, so it can be read without synchronization.
 	config Config
 	// closed indicates if the modem has been shut down
-	closed bool
+	closed atomic.Bool
+	// done is closed by Close to release the Loop and pending commands
+	done chan struct{}
 	// loopRunning indicates if the Loop is currently running
-	loopRunning bool
-	// atTimeout is the default timeout for AT command responses
-	atTimeout time.Duration
-	// simPIN is the SIM card PIN code for authentication
-	simPIN string
+	loopRunning atomic.Bool
+	// ready indicates the modem registered to the network during init
+	ready atomic.Bool
+	// pacer enforces the minimum interval between message submissions
+	pacer *pacer
+	// sending holds message submissions while the modem is paused
+	sending gate
+	// submitting holds a token while a message is being submitted
+	submitting chan struct{}
+	// queued counts the messages waiting to be sent, including the one
+	// being submitted
+	queued atomic.Int32
+	// moreMessagesUnsupported is set once the modem rejected AT+CMMS
+	moreMessagesUnsupported atomic.Bool
+	// concatRef numbers the concatenated messages sent in PDU mode
+	concatRef atomic.Uint32
+	// diag counts and reports the irregular conditions handled by the Loop
+	diag *diagnostics
+	// urcLimiter coalesces URC floods, nil without rate limits. It is only
+	// used by the Loop.
+	urcLimiter *urcLimiter
+	// learner collects unrecognized lines while LearnURCs runs, nil
+	// otherwise
+	learner atomic.Pointer[urcLearner]
+	// history keeps the latest lifecycle events
+	history *history
+	// simState is the SIM state last seen, to record its changes
+	simState atomic.Int32
+	// inFlight describes the command in flight for State, nil if none
+	inFlight atomic.Pointer[PendingCommand]
+
+	// errMu guards lastErr
+	errMu sync.Mutex
+	// lastErr is the error that terminated the most recent Loop run
+	lastErr error
 
 	// Communication channels for Loop coordination
 	// urcChan receives Unsolicited Result Codes from the modem
 	urcChan chan string
-	// commands queues AT command requests for the Loop to process
+	// commands queues AT command requests for the Loop to process. Message
+	// submissions use it, it takes precedence over telemetry.
 	commands chan *commandRequest
+	// telemetry queues status polls, which yield to message submissions
+	// but are not starved by them, see messageBurst
+	telemetry chan *commandRequest
+	// aborts requests the Loop to abort the command in flight, the channel
+	// sent is closed once it did
+	aborts chan chan struct{}
 
 	// Loop control
 	// loopCtx controls the lifecycle of the main event loop
@@ -51,6 +94,24 @@ type commandRequest struct {
 	respChan chan commandResponse
 	// ctx provides timeout and cancellation control for the command
 	ctx context.Context
+	// written is when the Loop wrote the command to the transport
+	written time.Time
+}
+
+// requestPool recycles command requests along with their response channel.
+// A request may only be released once the Loop answered it or if it never
+// reached the Loop, an abandoned request may still receive its answer.
+var requestPool = sync.Pool{
+	New: func() any {
+		// Buffered to prevent blocking
+		return &commandRequest{respChan: make(chan commandResponse, 1)}
+	},
+}
+
+// releaseRequest returns req to requestPool.
+func releaseRequest(req *commandRequest) {
+	*req = commandRequest{respChan: req.respChan}
+	requestPool.Put(req)
 }
 
 // commandResponse contains the result of an AT command execution.
@@ -62,7 +123,36 @@ type commandResponse struct {
 	err error
 }
 
+// ProfileReset selects the modem profile restored at the start of
+// initialization, before the gateway applies its own settings.
+type ProfileReset int
+
+const (
+	// ProfileKeep keeps the modem's current settings
+	ProfileKeep ProfileReset = iota
+	// ProfileStored restores the profile saved in the modem's NVRAM (ATZ)
+	ProfileStored
+	// ProfileFactory restores the factory defaults (AT&F)
+	ProfileFactory
+)
+
+// command returns the AT command restoring the profile, or an empty string
+// if the settings are kept.
+func (p ProfileReset) command() string {
+	switch p {
+	case ProfileStored:
+		return at.CmdResetProfile
+	case ProfileFactory:
+		return at.CmdFactoryReset
+	default:
+		return ""
+	}
+}
+
 // PollConfig defines configuration for polling operations like waiting for SIM readiness.
+//
+// Zero fields select defaults: a 500ms interval and a 30s timeout. Polling
+// stops at whichever of Timeout and MaxRetries is reached first.
 type PollConfig struct {
 	// Interval is the time between polling attempts
 	Interval time.Duration
@@ -72,9 +162,39 @@ type PollConfig struct {
 	MaxRetries int
 }
 
+// validate checks the PollConfig for values that can never poll sensibly.
+// The interval is compared after defaults are applied, so that a field left
+// zero cannot rule out every attempt.
+func (c PollConfig) validate() error {
+	if c.Interval < 0 || c.Timeout < 0 || c.MaxRetries < 0 {
+		return fmt.Errorf("%w: negative value in %+v", ErrInvalidPollConfig, c)
+	}
+	if d := c.withDefaults(); d.Interval > d.Timeout {
+		return fmt.Errorf("%w: interval %s exceeds timeout %s", ErrInvalidPollConfig, d.Interval, d.Timeout)
+	}
+	return nil
+}
+
+// withDefaults returns a copy of the PollConfig with zero fields replaced by
+// defaults and MaxRetries capped to what fits into Timeout.
+func (c PollConfig) withDefaults() PollConfig {
+	if c.Interval <= 0 {
+		c.Interval = 500 * time.Millisecond
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if fit := int(c.Timeout / c.Interval); c.MaxRetries <= 0 || c.MaxRetries > fit {
+		c.MaxRetries = fit
+	}
+	return c
+}
+
 // New creates a new Modem instance with the given configuration.
 // It establishes the transport connection, initializes the modem
 // hardware with common actions and prepares the event loop context.
+// Initialization can take a while (SIM unlock, network attach), its
+// progress is reported to the callback set with WithInitProgress.
 //
 // Returns an error if the transport connection or modem initialization
 // fails.
@@ -87,13 +207,22 @@ func New(ctx context.Context, config Config) (*Modem, error) {
 		return nil, err
 	}
 
+	config = config.snapshot()
 	m := &Modem{
-		atTimeout: config.atTimeout,
-		simPIN:    config.simPIN,
+		config:    config,
 		transport: transport,
 		urcChan:   make(chan string, 100), // Buffered to prevent blocking on URCs
 		// No queue for commands
-		commands: make(chan *commandRequest),
+		commands:  make(chan *commandRequest),
+		telemetry: make(chan *commandRequest),
+		aborts:    make(chan chan struct{}),
+		done:      make(chan struct{}),
+		pacer:     newPacer(config.clock, config.minSendInterval),
+		// One message is submitted at a time
+		submitting: make(chan struct{}, 1),
+		diag:       newDiagnostics(config.diagnostics, config.metrics),
+		urcLimiter: newURCLimiter(config.urcLimits),
+		history:    newHistory(config.historySize),
 	}
 
 	// Prepare context for Loop (but don't start it yet)
@@ -114,14 +243,25 @@ func New(ctx context.Context, config Config) (*Modem, error) {
 		return nil, fmt.Errorf("initialize modem: %w", err)
 	}
 
+	// Initialization waits for the SIM to become ready
+	m.simState.Store(int32(SIMReady))
+	if m.Ready() {
+		m.recordLifecycle(LifecycleInitialized, "registered")
+	} else {
+		m.recordLifecycle(LifecycleInitialized, "not registered")
+	}
 	return m, nil
 }
 
+// messageBurst is the number of message commands the Loop writes in a row
+// while a status poll is waiting, before it lets the poll through.
+const messageBurst = 4
+
 // Loop is the main event loop that handles all transport I/O operations.
 // It must be called exactly once after New() and before any other modem operations.
 // The Loop coordinates all communication with the modem hardware:
 //
-// 1. Processes command requests from exec() calls
+// 1. Processes command requests from exec() calls, messages before polls
 // 2. Writes AT commands to the transport
 // 3. Reads and parses responses from the transport
 // 4. Dispatches URCs (Unsolicited Result Codes) to subscribers
@@ -131,6 +271,16 @@ func New(ctx context.Context, config Config) (*Modem, error) {
 // that reads from the transport, preventing race conditions and ensuring URCs
 // are never lost.
 //
+// A panic while reading from the transport restarts the reader up to the
+// number of times set with WithReaderRestarts. Panics beyond that, or in the Loop itself,
+// are recovered and returned as a *PanicError. The error that terminated
+// the Loop is also available through Err().
+//
+// Final responses arriving with no command pending are counted (see
+// OrphanedResponses) and logged. If resync is enabled, repeated orphans
+// flush the queued input and verify the modem answers an AT ping before
+// further commands are written.
+//
 // Usage:
 //
 //	modem, err := New(ctx, config)
@@ -141,16 +291,26 @@ func New(ctx context.Context, config Config) (*Modem, error) {
 //
 //	// Now exec() calls will work
 //	resp, err := modem.exec(ctx, "AT")
-func (m *Modem) Loop(ctx context.Context) error {
-	if m.loopRunning {
+func (m *Modem) Loop(ctx context.Context) (err error) {
+	if !m.loopRunning.CompareAndSwap(false, true) {
 		return ErrLoopRunning
 	}
-	m.loopRunning = true
+	m.recordLifecycle(LifecycleLoopStarted, "")
 	defer func() {
-		m.loopRunning = false
+		if r := recover(); r != nil {
+			err = newPanicError("loop", r)
+		}
+		// Cancellation is a regular shutdown, not a failure
+		if err != nil && ctx.Err() == nil {
+			m.setErr(err)
+		}
+		detail := ""
+		if err != nil {
+			detail = err.Error()
+		}
+		m.recordLifecycle(LifecycleLoopStopped, detail)
+		m.loopRunning.Store(false)
 	}()
-	scanner := bufio.NewScanner(m.transport)
-	scanner.Split(at.Splitter)
 
 	// Channels for tokens and errors from the scanner goroutine
 	tokens := make(chan string, 10)
@@ -161,30 +321,207 @@ func (m *Modem) Loop(ctx context.Context) error {
 		defer func() {
 			close(tokens)
 		}()
-		for scanner.Scan() {
-			token := scanner.Text()
-			if token != "" {
+		for restarts := 0; ; restarts++ {
+			err := m.readTokens(ctx, tokens)
+			var panicErr *PanicError
+			if errors.As(err, &panicErr) && restarts < m.config.readerRestarts {
+				// Restart the reader with a fresh scanner
+				continue
+			}
+			// Scanner stopped - check if there was an error
+			if err != nil {
 				select {
-				case tokens <- token:
+				case scanErrs <- err:
 				case <-ctx.Done():
-					return
 				}
 			}
-		}
-		// Scanner stopped - check if there was an error
-		if err := scanner.Err(); err != nil {
-			select {
-			case scanErrs <- err:
-			case <-ctx.Done():
-			}
+			return
 		}
 	}()
 
+	if m.config.driftCheck > 0 {
+		stop := make(chan struct{})
+		defer close(stop)
+		go m.watchConfig(ctx, stop)
+	}
+
 	// Current command being processed
 	var currentCmd *commandRequest
+	// currentLines and wire are reused for every command, the response is
+	// joined into a new string and transports must not retain written data
 	var currentLines []string
+	var wire []byte
+
+	// Assembles multi-line URCs from their header and payload lines
+	urcs := at.NewURCAssembler(m.config.allURCFrames())
+
+	// Orphaned final responses since the last completed command
+	orphanStreak := 0
+	// Commands that timed out before their final response arrived. Their
+	// late responses are discarded instead of being taken for the answer
+	// to a later command.
+	aborted := 0
+	// Fires when an outstanding resync ping timed out, nil otherwise
+	var pingTimeout <-chan time.Time
+	var pingTimer Timer
+	// inPrompt is set while the modem waits for SMS text after a prompt
+	inPrompt := false
+
+	// keepalive is set while the outstanding ping is a keepalive ping
+	keepalive := false
+	// idle is cleared by any traffic between two keepalive ticks
+	idle := true
+	var keepaliveTick <-chan time.Time
+	if m.config.keepalive > 0 {
+		ticker := m.config.clock.NewTicker(m.config.keepalive)
+		defer ticker.Stop()
+		keepaliveTick = ticker.C()
+	}
+
+	// ping writes an AT ping. Commands are held back until the modem
+	// answered it.
+	ping := func() error {
+		if _, err := m.transport.Write([]byte(at.CmdAt + "\r")); err != nil {
+			return fmt.Errorf("write ping: %w", err)
+		}
+		timeout := m.config.atTimeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		pingTimer = m.config.clock.NewTimer(timeout)
+		pingTimeout = pingTimer.C()
+		return nil
+	}
+
+	// startPing writes a resync ping. Its answer proves that responses are
+	// paired with commands again.
+	startPing := func() error {
+		keepalive = false
+		if err := ping(); err != nil {
+			return err
+		}
+		m.diag.record(EventResync, "")
+		return nil
+	}
+
+	// shutdown fails the command in flight when the modem is closed. The
+	// reader errors caused by closing the transport are not reported.
+	shutdown := func() error {
+		if currentCmd != nil {
+			currentCmd.respChan <- commandResponse{err: fmt.Errorf("modem closed while waiting for response: %w", ErrAlreadyClosed)}
+			currentCmd = nil
+		}
+		return nil
+	}
+
+	// abort fails the command in flight with reason, or leaves SMS text
+	// entry if the modem is waiting at the prompt, and resyncs. A late
+	// answer to the aborted command is discarded.
+	abort := func(reason error) error {
+		switch {
+		case currentCmd != nil:
+			currentCmd.respChan <- commandResponse{err: reason}
+			m.config.logger.Warn("command aborted", "err", reason)
+			currentCmd = nil
+			currentLines = currentLines[:0]
+			aborted++
+		case inPrompt:
+			// Discard the message being entered without sending it
+			if _, err := m.transport.Write([]byte(at.Esc)); err != nil {
+				return fmt.Errorf("write escape: %w", err)
+			}
+			inPrompt = false
+		default:
+			// Nothing in flight
+			return nil
+		}
+		if pingTimeout == nil {
+			return startPing()
+		}
+		return nil
+	}
+
+	// writeCommand writes req to the transport and makes it the command in
+	// flight. A write error is reported to the caller of exec.
+	writeCommand := func(req *commandRequest) {
+		currentCmd = req
+		currentLines = currentLines[:0]
+
+		req.written = m.config.clock.Now()
+		m.inFlight.Store(&PendingCommand{Command: commandName(req.cmd), Since: req.written})
+
+		// Write the AT command to the transport
+		wire = append(append(wire[:0], strings.TrimSpace(req.cmd)...), '\r')
+		if _, err := m.transport.Write(wire); err != nil {
+			req.respChan <- commandResponse{err: fmt.Errorf("write command %q: %w", req.cmd, err)}
+			currentCmd = nil
+			m.inFlight.Store(nil)
+			return
+		}
+		idle = false
+		// Whatever was written ended a pending text entry
+		inPrompt = false
+	}
+	// Message commands written since the last telemetry command
+	messageStreak := 0
+
+	// Fires when a URC held back by a rate limit is due, nil otherwise
+	var urcDue <-chan time.Time
+	var urcTimer Timer
+	defer func() {
+		if urcTimer != nil {
+			urcTimer.Stop()
+		}
+	}()
+
+	defer m.inFlight.Store(nil)
 
 	for {
+		if currentCmd == nil && m.inFlight.Load() != nil {
+			m.inFlight.Store(nil)
+		}
+		if urcDue == nil && m.urcLimiter != nil {
+			if d, ok := m.urcLimiter.next(m.config.clock.Now()); ok {
+				urcTimer = m.config.clock.NewTimer(d)
+				urcDue = urcTimer.C()
+			}
+		}
+
+		// A single command is in flight at a time, and none is written
+		// while a resync ping is outstanding
+		commands, telemetry := m.commands, m.telemetry
+		if currentCmd != nil || pingTimeout != nil {
+			commands, telemetry = nil, nil
+		}
+		if inPrompt {
+			// The message text comes through the message lane, a status
+			// poll would be taken as part of it
+			telemetry = nil
+		}
+
+		// Message commands go first, unless telemetry has been waiting
+		// for a burst of them. The select below picks either lane.
+		preferred := commands
+		if messageStreak >= messageBurst && telemetry != nil {
+			preferred = telemetry
+		}
+		select {
+		case req := <-preferred:
+			if preferred == telemetry {
+				messageStreak = 0
+			} else {
+				messageStreak++
+			}
+			writeCommand(req)
+			continue
+		default:
+		}
+
+		var cmdDone <-chan struct{}
+		if currentCmd != nil {
+			cmdDone = currentCmd.ctx.Done()
+		}
+
 		select {
 		case <-ctx.Done():
 			// Context cancelled - shut down gracefully
@@ -193,50 +530,113 @@ func (m *Modem) Loop(ctx context.Context) error {
 			}
 			return ctx.Err()
 
-		case req := <-m.commands:
-			currentCmd = req
-			currentLines = nil
+		case <-m.done:
+			return shutdown()
 
-			// Write the AT command to the transport
-			wire := strings.TrimSpace(req.cmd) + "\r"
-			if _, err := m.transport.Write([]byte(wire)); err != nil {
-				req.respChan <- commandResponse{err: fmt.Errorf("write command %q: %w", req.cmd, err)}
-				currentCmd = nil
-				continue
+		case <-cmdDone:
+			// Command timed out or was cancelled. The modem may still
+			// answer it, so resync before the next command is written.
+			if err := abort(fmt.Errorf("command timeout: %w", currentCmd.ctx.Err())); err != nil {
+				return err
+			}
+
+		case done := <-m.aborts:
+			if err := abort(ErrAborted); err != nil {
+				return err
+			}
+			close(done)
+
+		case <-keepaliveTick:
+			// Only an idle link is pinged, and one ping is outstanding at
+			// a time
+			if idle && currentCmd == nil && pingTimeout == nil && !inPrompt {
+				keepalive = true
+				if err := ping(); err != nil {
+					return err
+				}
+			}
+			idle = true
+
+		case <-urcDue:
+			urcDue, urcTimer = nil, nil
+			for _, urc := range m.urcLimiter.due(m.config.clock.Now()) {
+				m.deliverURC(urc)
+			}
+
+		case <-pingTimeout:
+			if keepalive {
+				return ErrKeepaliveTimeout
 			}
+			// The late responses, if any, got lost along with the ping
+			m.config.logger.Warn("modem did not answer resync ping", "aborted", aborted)
+			pingTimeout, pingTimer = nil, nil
+			aborted = 0
+
+		case req := <-commands:
+			messageStreak++
+			writeCommand(req)
+
+		case req := <-telemetry:
+			messageStreak = 0
+			writeCommand(req)
 
 		case token, ok := <-tokens:
 			if !ok {
+				if m.closed.Load() {
+					return shutdown()
+				}
+				// Token channel closed - scanner stopped. A reader error is
+				// reported before the channel is closed and takes precedence.
+				select {
+				case err := <-scanErrs:
+					if currentCmd != nil {
+						currentCmd.respChan <- commandResponse{err: fmt.Errorf("read error: %w", err)}
+					}
+					return fmt.Errorf("scanner error: %w", err)
+				default:
+				}
 
-				// Token channel closed - scanner stopped
 				if currentCmd != nil {
 					currentCmd.respChan <- commandResponse{response: token, err: io.EOF}
 					currentCmd = nil
-					currentLines = nil
+					currentLines = currentLines[:0]
 				}
 				return io.EOF
 			}
+			idle = false
+
+			// Payload line of a multi-line URC such as +CMT. It belongs to
+			// the URC regardless of how it would classify on its own.
+			if urcs.Pending() {
+				if urc, ok := urcs.Add(token); ok {
+					m.dispatchURC(urc)
+				}
+				continue
+			}
+			if token == "" {
+				// Blank lines only matter for URC framing
+				continue
+			}
+			if urcs.Begin(token) {
+				// Dispatched once its payload lines arrived
+				continue
+			}
 
 			// Classify the token to determine how to handle it
-			respType := at.Classify(token)
+			respType := m.config.classifier.Classify(token)
 
 			switch respType {
 			case at.TypeURC:
 				// Unsolicited Result Code - always dispatch to URC channel
 				// URCs can arrive at any time, even during command execution
-				select {
-				case m.urcChan <- token:
-					// URC dispatched successfully
-				default:
-					// URC channel is full - drop the URC
-					// In production, you might want to log this
-				}
+				m.dispatchURC(token)
 
 			case at.TypeFinal:
 				// Final response (OK, ERROR, +CME ERROR, etc.)
 				if currentCmd != nil {
 					currentLines = append(currentLines, token)
 					response := strings.Join(currentLines, "\n")
+					m.observeCommand(currentCmd)
 
 					if token == at.OK {
 						// Command succeeded
@@ -247,53 +647,245 @@ func (m *Modem) Loop(ctx context.Context) error {
 					}
 
 					currentCmd = nil
-					currentLines = nil
+					currentLines = currentLines[:0]
+					orphanStreak = 0
+					break
+				}
+
+				if aborted > 0 {
+					// Late answer to a timed out command
+					aborted--
+					m.diag.record(EventLateResponse, token)
+					m.config.logger.Debug("discarded late response", "response", token)
+					break
+				}
+
+				if pingTimeout != nil {
+					pingTimer.Stop()
+					pingTimeout, pingTimer = nil, nil
+					if keepalive {
+						// The answer does not count as traffic
+						keepalive, idle = false, true
+						m.config.logger.Debug("modem answered keepalive ping", "response", token)
+						break
+					}
+					// Answer to the resync ping, the modem is back in sync
+					orphanStreak = 0
+					m.config.logger.Info("modem resynchronized", "response", token)
+					break
+				}
+
+				// No command is pending, usually a sign of a desync such
+				// as a late answer to a timed out command
+				orphanStreak++
+				m.diag.record(EventOrphanedResponse, token)
+				m.config.logger.Warn("orphaned final response", "response", token, "consecutive", orphanStreak)
+				if m.config.orphanResync > 0 && orphanStreak >= m.config.orphanResync {
+					if err := m.flushTokens(tokens, urcs); err != nil {
+						return err
+					}
+					if err := startPing(); err != nil {
+						return err
+					}
 				}
-				// If no current command, ignore the final response (orphaned)
 
 			case at.TypeData:
 				// Intermediate data response (e.g., +CSQ: 15,99)
 				if currentCmd != nil {
 					currentLines = append(currentLines, token)
+					break
+				}
+				// No command is pending, ignore the data
+				m.diag.record(EventOrphanedData, token)
+				if learner := m.learner.Load(); learner != nil {
+					learner.record(token)
 				}
-				// If no current command, ignore the data (orphaned)
 
 			case at.TypePrompt:
 				// SMS prompt (">") - return immediately for SMS text input
 				if currentCmd != nil {
 					currentLines = append(currentLines, token)
 					response := strings.Join(currentLines, "\n")
+					m.observeCommand(currentCmd)
 					currentCmd.respChan <- commandResponse{response: response}
 					currentCmd = nil
-					currentLines = nil
+					currentLines = currentLines[:0]
+					inPrompt = true
+					break
 				}
-			}
 
-			// Check if current command has timed out
-			if currentCmd != nil {
-				select {
-				case <-currentCmd.ctx.Done():
-					// Command timed out or was cancelled
-					currentCmd.respChan <- commandResponse{err: fmt.Errorf("command timeout: %w", currentCmd.ctx.Err())}
-					currentCmd = nil
-					currentLines = nil
-				default:
-					// Command still within timeout
+				// Late prompt of an aborted command. Nobody is going to
+				// enter the text, so leave text entry right away.
+				if _, err := m.transport.Write([]byte(at.Esc)); err != nil {
+					return fmt.Errorf("write escape: %w", err)
+				}
+				m.diag.record(EventLatePrompt, token)
+				if aborted > 0 {
+					aborted--
+				}
+				if pingTimeout != nil {
+					// The outstanding ping was taken as message text
+					pingTimer.Stop()
+					if err := startPing(); err != nil {
+						return err
+					}
 				}
 			}
 
 		case err := <-scanErrs:
+			if m.closed.Load() {
+				return shutdown()
+			}
 			// Scanner error - notify current command if any
 			if currentCmd != nil {
 				currentCmd.respChan <- commandResponse{err: fmt.Errorf("read error: %w", err)}
 				currentCmd = nil
-				currentLines = nil
+				currentLines = currentLines[:0]
 			}
 			return fmt.Errorf("scanner error: %w", err)
 		}
 	}
 }
 
+// flushTokens discards the response lines already queued by the reader
+// before resynchronizing after repeated orphaned responses. URCs among them
+// are still dispatched.
+func (m *Modem) flushTokens(tokens <-chan string, urcs *at.URCAssembler) error {
+	m.config.logger.Warn("resynchronizing modem")
+	urcs.Reset()
+
+	for flushing := true; flushing; {
+		select {
+		case token, ok := <-tokens:
+			if !ok {
+				return io.EOF
+			}
+			// Framed URCs are dropped, their payload may be incomplete
+			if m.config.classifier.Classify(token) == at.TypeURC && !urcs.Begin(token) {
+				m.dispatchURC(token)
+			}
+			urcs.Reset()
+		default:
+			flushing = false
+		}
+	}
+	return nil
+}
+
+// Abort cancels the command in flight, which then fails with ErrAborted.
+// If the modem waits for SMS text at the prompt, the text entry is left
+// with ESC instead, discarding the message. Either way the Loop resyncs
+// with an AT ping before writing the next command, and a late answer to
+// the aborted command is discarded. If nothing is in flight, Abort does
+// nothing.
+//
+// Commands whose context is done are aborted the same way automatically.
+// Abort returns once the Loop handled the request, or when ctx is done.
+func (m *Modem) Abort(ctx context.Context) error {
+	if m.closed.Load() {
+		return ErrAlreadyClosed
+	}
+
+	done := make(chan struct{})
+	select {
+	case m.aborts <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.done:
+		return ErrAlreadyClosed
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-m.done:
+		return ErrAlreadyClosed
+	}
+}
+
+// OrphanedResponses returns the number of final responses received while
+// no command was pending. A growing count indicates the command/response
+// pairing got out of sync, for example after a timed out command.
+func (m *Modem) OrphanedResponses() uint64 {
+	return m.diag.count(EventOrphanedResponse)
+}
+
+// dispatchURC delivers a URC to the URC channel without blocking the Loop,
+// unless its rate limit holds it back.
+func (m *Modem) dispatchURC(urc string) {
+	if m.urcLimiter != nil {
+		ok, suppressed := m.urcLimiter.admit(urc, m.config.clock.Now())
+		if suppressed != "" {
+			m.diag.record(EventURCSuppressed, suppressed)
+		}
+		if !ok {
+			return
+		}
+	}
+	m.deliverURC(urc)
+}
+
+// deliverURC puts a URC on the URC channel, dropping it if the channel is
+// full.
+func (m *Modem) deliverURC(urc string) {
+	select {
+	case m.urcChan <- urc:
+		// URC dispatched successfully
+	default:
+		// URC channel is full - drop the URC
+		m.diag.record(EventURCDropped, urc)
+		m.config.logger.Warn("URC channel full, dropping URC", "urc", urc)
+	}
+}
+
+// observeCommand records how long the modem took to answer cmd.
+func (m *Modem) observeCommand(cmd *commandRequest) {
+	d := m.config.clock.Now().Sub(cmd.written)
+	m.config.metrics.ObserveHistogram(MetricCommandDuration, d.Seconds())
+}
+
+// readTokens scans the transport and forwards all tokens until the
+// transport is exhausted or ctx is done. A panic raised while reading is
+// recovered and returned as a *PanicError.
+func (m *Modem) readTokens(ctx context.Context, tokens chan<- string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError("reader", r)
+		}
+	}()
+
+	scanner := bufio.NewScanner(m.transport)
+	scanner.Split(at.Splitter)
+
+	// Blank lines are forwarded as well, they terminate some framed URCs
+	for scanner.Scan() {
+		select {
+		case tokens <- scanner.Text():
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Err returns the error that terminated the most recent Loop run, such as a
+// transport failure or a recovered panic. It returns nil if the Loop has not
+// failed, and does not report regular shutdown through context cancellation.
+func (m *Modem) Err() error {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	return m.lastErr
+}
+
+// setErr records err as the last fatal Loop error.
+func (m *Modem) setErr(err error) {
+	m.errMu.Lock()
+	defer m.errMu.Unlock()
+	m.lastErr = err
+}
+
 // URC returns a read-only channel that receives Unsolicited Result Codes.
 // These are asynchronous notifications from the modem (e.g., incoming SMS,
 // network status changes, etc.). The channel is buffered, but may drop
@@ -305,13 +897,16 @@ func (m *Modem) URC() <-chan string {
 // Close shuts down the modem and releases all resources.
 // It stops the event loop, closes the transport connection, and marks
 // the modem as closed. After calling Close(), the modem cannot be reused.
+//
+// Commands still waiting for the modem fail with an error wrapping
+// ErrAlreadyClosed rather than with the transport error caused by closing
+// it, and a running Loop returns nil.
 func (m *Modem) Close() error {
-
-	if m.closed {
+	if !m.closed.CompareAndSwap(false, true) {
 		return ErrAlreadyClosed
 	}
-
-	m.closed = true
+	close(m.done)
+	m.recordLifecycle(LifecycleClosed, "")
 
 	// Stop the Loop if it's running
 	if m.loopCancel != nil {
@@ -328,60 +923,245 @@ func (m *Modem) Close() error {
 // init performs the initial setup sequence for the modem hardware.
 // This method is called during New() and must complete successfully
 // before the modem can be used.
-func (m *Modem) init(ctx context.Context) error {
+func (m *Modem) init(ctx context.Context) (err error) {
+	progress := &initTracker{report: m.config.initProgress}
+	defer func() {
+		if err != nil {
+			progress.fail(err)
+			return
+		}
+		progress.complete()
+	}()
+
+	// 0. Discard boot URCs and partial lines left in the input, they would
+	// be taken for the answer to the first command
+	if m.config.drainWindow > 0 {
+		if _, ok := m.transport.(readTimeouter); ok {
+			progress.begin(InitStepDrain)
+			if err := m.drain(ctx); err != nil {
+				return fmt.Errorf("drain stale input: %w", err)
+			}
+		}
+	}
+
 	// 1. Wake-up / sanity check
+	progress.begin(InitStepHandshake)
 	if err := m.expectOkDirect(ctx, at.CmdAt); err != nil {
 		return fmt.Errorf("modem not responding: %w", err)
 	}
 
+	// 2. Start from a known profile, whatever a previous user configured
+	if cmd := m.config.profileReset.command(); cmd != "" {
+		progress.begin(InitStepProfileReset)
+		if err := m.expectOkDirect(ctx, cmd); err != nil {
+			return fmt.Errorf("reset modem profile: %w", err)
+		}
+	}
+
+	progress.begin(InitStepEchoOff)
 	if err := m.expectOkDirect(ctx, at.CmdEchoOff); err != nil {
 		return fmt.Errorf("could not disable echo: %w", err)
 	}
 
+	progress.begin(InitStepVerboseErrors)
 	if err := m.expectOkDirect(ctx, at.CmdVerboseErrors); err != nil {
 		return fmt.Errorf("could not enable verbose errors: %w", err)
 	}
 
 	// 4. Check SIM status
-	simStatus, err := m.execDirect(ctx, at.CmdSimStatus)
+	progress.begin(InitStepSIMStatus)
+	simState, err := parseSIMState(m.execDirect(ctx, at.CmdSimStatus))
 	if err != nil {
 		return fmt.Errorf("query SIM status: %w", err)
 	}
 
-	switch {
-	case strings.Contains(simStatus, at.SimReady):
+	switch simState {
+	case SIMReady:
 		// OK
 
-	case strings.Contains(simStatus, at.SimPin):
-		if m.simPIN == "" {
+	case SIMPINRequired:
+		if m.config.simPIN == "" {
 			return ErrSIMPinRequired
 		}
-		if err := m.expectOkDirect(ctx, fmt.Sprintf(`AT+CPIN="%s"`, m.simPIN)); err != nil {
+		progress.begin(InitStepSIMPIN)
+		// A wrong PIN on the last attempt would block the SIM, better leave
+		// it to a human then
+		attempts, ok, err := m.pinAttemptsDirect(ctx)
+		if err != nil {
+			return fmt.Errorf("query PIN attempts: %w", err)
+		}
+		if ok && attempts <= 1 {
+			return fmt.Errorf("%w: %d left", ErrSIMPinAttemptsLow, attempts)
+		}
+		if !ok {
+			m.config.logger.Warn("cannot query remaining PIN attempts, entering PIN anyway")
+		}
+		if err := m.expectOkDirect(ctx, at.CPIN(m.config.simPIN)); err != nil {
 			return fmt.Errorf("enter SIM PIN: %w", err)
 		}
 
 		// Wait until SIM becomes ready
-		if err := m.waitForSIMReady(ctx, PollConfig{}); err != nil {
+		progress.begin(InitStepSIMReady)
+		if err := m.waitForSIMReady(ctx, m.config.simReadyPolling); err != nil {
+			return err
+		}
+
+	case SIMBusy:
+		// Still starting up right after power on
+		progress.begin(InitStepSIMReady)
+		if err := m.waitForSIMReady(ctx, m.config.simReadyPolling); err != nil {
 			return err
 		}
 
 	default:
-		return fmt.Errorf("unsupported SIM state: %q", simStatus)
+		return fmt.Errorf("unsupported SIM state: %s", simState)
 	}
 
 	// 5. Select SMS text mode
+	progress.begin(InitStepTextMode)
 	if err := m.expectOkDirect(ctx, at.CmdSetTextMode); err != nil {
 		return fmt.Errorf("set SMS text mode: %w", err)
 	}
 
+	// 6. Select the character set text mode messages are encoded in
+	progress.begin(InitStepCharset)
+	if err := m.selectCharset(ctx); err != nil {
+		return fmt.Errorf("select character set: %w", err)
+	}
+
+	// 7. Configure how incoming messages are reported, if requested
+	if cmd := m.config.newMessageMode.command(); cmd != "" {
+		progress.begin(InitStepNewMessage)
+		if err := m.expectOkDirect(ctx, cmd); err != nil {
+			return fmt.Errorf("configure new message indication: %w", err)
+		}
+	}
+
+	// 8. Persist the settings, so a modem-side reset keeps them until the
+	// gateway initializes the modem again
+	if m.config.saveProfile {
+		progress.begin(InitStepSaveProfile)
+		if err := m.expectOkDirect(ctx, at.CmdSaveProfile); err != nil {
+			return fmt.Errorf("save modem profile: %w", err)
+		}
+	}
+
+	// 9. Wait for network registration, sending fails until then. Not being
+	// registered yet is not fatal, the modem is just reported as not ready.
+	if m.config.registrationWait {
+		progress.begin(InitStepRegistration)
+		err := m.waitForRegistration(ctx, m.config.registrationPolling)
+		if errors.Is(err, ErrNotRegistered) {
+			progress.fail(err)
+			m.config.metrics.SetGauge(MetricReady, 0)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	m.ready.Store(true)
+	m.config.metrics.SetGauge(MetricReady, 1)
+
+	return nil
+}
+
+// drainLimit bounds how much stale input is discarded before giving up on
+// the line becoming quiet, e.g. because the modem keeps sending URCs.
+const drainLimit = 64 * 1024
+
+// drain discards whatever the modem sends until it has been quiet for the
+// drain window. It requires a transport supporting read timeouts, the
+// buffered input is additionally purged if the transport supports that.
+func (m *Modem) drain(ctx context.Context) (err error) {
+	rt := m.transport.(readTimeouter)
+	if f, ok := m.transport.(inputFlusher); ok {
+		if err := f.ResetInputBuffer(); err != nil {
+			return fmt.Errorf("reset input buffer: %w", err)
+		}
+	}
+
+	if err := rt.SetReadTimeout(m.config.drainWindow); err != nil {
+		return fmt.Errorf("set read timeout: %w", err)
+	}
+	defer func() {
+		// Reads must block again, the Loop relies on that
+		if rerr := rt.SetReadTimeout(serial.NoTimeout); rerr != nil && err == nil {
+			err = fmt.Errorf("restore read timeout: %w", rerr)
+		}
+	}()
+
+	buf := make([]byte, 256)
+	discarded := 0
+	for discarded < drainLimit {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := m.transport.Read(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// Quiet for the whole window
+			break
+		}
+		m.config.logger.Debug("discarded stale input", "data", string(buf[:n]))
+		discarded += n
+	}
+	return nil
+}
+
+// selectCharset selects the configured TE character set and verifies that
+// the modem actually switched to it, as some firmwares accept AT+CSCS but
+// keep their default.
+func (m *Modem) selectCharset(ctx context.Context) error {
+	if err := m.expectOkDirect(ctx, at.CSCS(m.config.charset)); err != nil {
+		return err
+	}
+
+	resp, err := m.execDirect(ctx, at.CmdCharset)
+	if err != nil {
+		return fmt.Errorf("query character set: %w", err)
+	}
+	params, ok := at.Params(resp, at.Charset)
+	if !ok {
+		return fmt.Errorf("unexpected character set response: %q", resp)
+	}
+	if got := strings.Trim(params, `"`); got != m.config.charset {
+		return fmt.Errorf("%w: selected %s, modem reports %s", ErrCharsetMismatch, m.config.charset, got)
+	}
 	return nil
 }
 
+// Ready reports whether the modem was registered to the network when
+// initialization finished. If the registration wait is disabled, the modem
+// is considered ready once initialized.
+func (m *Modem) Ready() bool {
+	return m.ready.Load()
+}
+
 // exec sends an AT command to the modem and waits for the response.
 // This method coordinates with the Loop() to ensure thread-safe command execution.
 // The Loop() must be running before calling this method.
 func (m *Modem) exec(ctx context.Context, cmd string) (string, error) {
-	if m.closed {
+	return m.execOn(ctx, m.commands, classOf(cmd), cmd)
+}
+
+// execAs is exec for a command whose class cannot be told from its text,
+// such as the message text stored with AT+CMGW.
+func (m *Modem) execAs(ctx context.Context, class CommandClass, cmd string) (string, error) {
+	return m.execOn(ctx, m.commands, class, cmd)
+}
+
+// execTelemetry is exec for status polls. They yield to the message
+// submissions sent with exec, so polling never delays an alarm SMS.
+func (m *Modem) execTelemetry(ctx context.Context, cmd string) (string, error) {
+	return m.execOn(ctx, m.telemetry, classOf(cmd), cmd)
+}
+
+// execOn queues the command of class on the given lane of the Loop.
+func (m *Modem) execOn(ctx context.Context, lane chan<- *commandRequest, class CommandClass, cmd string) (string, error) {
+	if m.closed.Load() {
 		return "", ErrAlreadyClosed
 	}
 
@@ -390,30 +1170,34 @@ func (m *Modem) exec(ctx context.Context, cmd string) (string, error) {
 	}
 
 	// Apply per-command timeout if context has none
-	if _, ok := ctx.Deadline(); !ok && m.config.atTimeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, m.config.atTimeout)
-		defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		if timeout := m.config.classTimeout(class); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
 	}
 
 	// Create command request
-	req := &commandRequest{
-		cmd:      cmd,
-		respChan: make(chan commandResponse, 1), // Buffered to prevent blocking
-		ctx:      ctx,
-	}
+	req := requestPool.Get().(*commandRequest)
+	req.cmd, req.ctx = cmd, ctx
 
 	// Send request to Loop
 	select {
-	case m.commands <- req:
+	case lane <- req:
 		// Request queued successfully
 	case <-ctx.Done():
+		releaseRequest(req)
 		return "", fmt.Errorf("command cancelled before sending: %w", ctx.Err())
+	case <-m.done:
+		releaseRequest(req)
+		return "", fmt.Errorf("command not sent, shutting down: %w", ErrAlreadyClosed)
 	}
 
-	// Wait for response from Loop
+	// Wait for response from Loop. The Loop answers on shutdown as well.
 	select {
 	case resp := <-req.respChan:
+		releaseRequest(req)
 		return resp.response, resp.err
 	case <-ctx.Done():
 		return "", fmt.Errorf("command timeout: %w", ctx.Err())
@@ -428,17 +1212,19 @@ func (m *Modem) exec(ctx context.Context, cmd string) (string, error) {
 // WARNING: This method should only be used during initialization.
 // Use exec() for normal operations.
 func (m *Modem) execDirect(ctx context.Context, cmd string) (string, error) {
-	if m.closed {
+	if m.closed.Load() {
 		return "", ErrAlreadyClosed
 	}
 	if m.transport == nil {
 		return "", ErrNotInitialized
 	}
 
-	if _, ok := ctx.Deadline(); !ok && m.atTimeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, m.atTimeout)
-		defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		if timeout := m.config.commandTimeout(cmd); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
 	}
 
 	wire := strings.TrimSpace(cmd) + "\r"
@@ -450,6 +1236,8 @@ func (m *Modem) execDirect(ctx context.Context, cmd string) (string, error) {
 	scanner.Split(at.Splitter)
 
 	var lines []string
+	// URCs are ignored, urcs only tracks their payload lines to skip them
+	urcs := at.NewURCAssembler(m.config.allURCFrames())
 
 	for {
 		select {
@@ -465,11 +1253,15 @@ func (m *Modem) execDirect(ctx context.Context, cmd string) (string, error) {
 		}
 
 		token := scanner.Text()
-		if token == "" {
+		if urcs.Pending() {
+			urcs.Add(token)
+			continue
+		}
+		if token == "" || urcs.Begin(token) {
 			continue
 		}
 
-		respType := at.Classify(token)
+		respType := m.config.classifier.Classify(token)
 
 		switch respType {
 		case at.TypeFinal:
@@ -517,46 +1309,88 @@ func (m *Modem) expectOkDirect(ctx context.Context, cmd string) error {
 // to authenticate and become operational. Uses configurable polling interval
 // and retry limits to avoid infinite waiting.
 func (m *Modem) waitForSIMReady(ctx context.Context, config PollConfig) error {
-	var (
-		pollInterval = config.Interval
-		timeout      = config.Timeout
-		maxRetries   = config.MaxRetries
-	)
+	err := m.pollDirect(ctx, config, func() (bool, error) {
+		state, err := parseSIMState(m.execDirect(ctx, at.CmdSimStatus))
+		if err != nil {
+			// Fail fast on critical errors
+			if isFatalDirectErr(err) {
+				return false, fmt.Errorf("SIM status check failed: %w", err)
+			}
+			return false, nil
+		}
+		return state == SIMReady, nil
+	})
+	if err != nil {
+		return fmt.Errorf("SIM not ready: %w", err)
+	}
+	return nil
+}
 
-	if pollInterval <= 0 {
-		pollInterval = 500 * time.Millisecond
+// waitForRegistration queries the network registration status until the
+// modem is registered to its home network or roaming. Running out of
+// retries or time is reported as ErrNotRegistered.
+func (m *Modem) waitForRegistration(ctx context.Context, config PollConfig) error {
+	check := func() (bool, error) {
+		resp, err := m.execDirect(ctx, at.CmdRegStatus)
+		if err != nil {
+			if isFatalDirectErr(err) {
+				return false, fmt.Errorf("registration check failed: %w", err)
+			}
+			return false, nil
+		}
+		return isRegistered(resp), nil
 	}
-	if timeout <= 0 {
-		timeout = 30 * time.Second
+
+	// The modem is usually registered already, so check before polling
+	if ok, err := check(); ok || err != nil {
+		return err
 	}
-	if maxRetries <= 0 {
-		maxRetries = int(timeout / pollInterval)
+
+	err := m.pollDirect(ctx, config, check)
+	if errors.Is(err, errPollExhausted) || ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrNotRegistered, err)
 	}
+	return err
+}
+
+// isRegistered reports whether a +CREG read response shows registration
+// to the home network (1) or roaming (5).
+func isRegistered(resp string) bool {
+	creg, err := at.ParseCREG(resp)
+	return err == nil && RegistrationStatus(creg.Stat).Registered()
+}
+
+// errPollExhausted is returned by pollDirect when the polled condition was
+// not met within the configured number of retries.
+var errPollExhausted = errors.New("retries exhausted")
 
-	ticker := time.NewTicker(pollInterval)
+// pollDirect calls check on every tick of the configured interval until it
+// reports done or fails, the retries are exhausted, or ctx is done.
+func (m *Modem) pollDirect(ctx context.Context, config PollConfig, check func() (bool, error)) error {
+	config = config.withDefaults()
+
+	ticker := m.config.clock.NewTicker(config.Interval)
 	defer ticker.Stop()
 	retries := 0
 
 	for {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("SIM not ready: %w", ctx.Err())
-		case <-ticker.C:
+			return ctx.Err()
+		case <-ticker.C():
 			retries++
-			if retries > maxRetries {
-				return fmt.Errorf("SIM not ready after %d retries", maxRetries)
-			}
-			resp, err := m.execDirect(ctx, at.CmdSimStatus)
-			if err != nil {
-				// Fail fast on critical errors
-				if errors.Is(err, ErrAlreadyClosed) || errors.Is(err, ErrNotInitialized) {
-					return fmt.Errorf("SIM status check failed: %w", err)
-				}
-				continue
+			if retries > config.MaxRetries {
+				return fmt.Errorf("%w after %d retries", errPollExhausted, config.MaxRetries)
 			}
-			if strings.Contains(resp, at.SimReady) {
-				return nil
+			if done, err := check(); done || err != nil {
+				return err
 			}
 		}
 	}
 }
+
+// isFatalDirectErr reports whether a direct exec error means the modem can
+// no longer be talked to, as opposed to a transient failure worth retrying.
+func isFatalDirectErr(err error) bool {
+	return errors.Is(err, ErrAlreadyClosed) || errors.Is(err, ErrNotInitialized)
+}
@@ -1,15 +1,18 @@
 package modem_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/at"
 	"i4.energy/across/smsgw/modem"
 )
 
@@ -93,68 +96,160 @@ func TestModemNew(t *testing.T) {
 		}
 	})
 
-	t.Run("Dialer error", func(t *testing.T) {
+	t.Run("Enters SIM PIN and polls until SIM is ready", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		mockTransport := modem.NewMockTransport(ctrl)
 		mockDialer := modem.NewMockDialer(ctrl)
-		mockDialer.EXPECT().Dial(gomock.Any()).Return(nil, errors.New("connection failed"))
 
+		calls := NewMockSequence(mockTransport).
+			AT().
+			EchoOff().
+			VerboseErrors().
+			SimPinRequired().
+			PINAttempts(3).
+			EnterPIN("1234").
+			SimPinRequired(). // still authenticating on first poll
+			SimReady().
+			SMSTextMode().
+			Charset("GSM").
+			Registration("1").
+			Build()
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				calls,
+			)...,
+		)
+
+		clock := newFakeClock()
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithSimPIN("1234").
+			WithSIMReadyPolling(modem.PollConfig{Interval: time.Second, MaxRetries: 5}).
+			WithClock(clock).
 			Build()
-
 		if err != nil {
-			t.Errorf("unexpected error from Build(): %v", err)
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
-		ctx := context.Background()
-		m, err := modem.New(ctx, config)
+		type result struct {
+			m   *modem.Modem
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			m, err := modem.New(context.Background(), config)
+			done <- result{m, err}
+		}()
 
-		if err == nil {
-			t.Error("expected error from dialer failure")
+		// Drive the SIM polling ticker with virtual time until New returns
+		clock.WaitForWaiter()
+		var res result
+	poll:
+		for {
+			clock.Advance(time.Second)
+			select {
+			case res = <-done:
+				break poll
+			case <-time.After(10 * time.Millisecond):
+			}
 		}
-		if m != nil {
-			t.Error("New() should return nil modem when dialer fails")
+
+		if res.err != nil {
+			t.Fatalf("unexpected error from New(): %v", res.err)
 		}
+		mockTransport.EXPECT().Close().Return(nil)
+		res.m.Close()
 	})
 
-	t.Run("ErrNoDialer when no dialer provided", func(t *testing.T) {
-		m, err := modem.New(context.Background(), modem.Config{})
-		if !errors.Is(err, modem.ErrNoDialer) {
-			t.Errorf("expected ErrNoDialer from New(), got: %v", err)
+	t.Run("ErrSIMPinAttemptsLow when one PIN attempt is left", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		// The PIN must not be entered
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			NewMockSequence(mockTransport).
+				AT().
+				EchoOff().
+				VerboseErrors().
+				SimPinRequired().
+				PINAttempts(1).
+				Build(),
+			[]any{
+				mockTransport.EXPECT().Close(),
+			},
+		)...)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithSimPIN("1234").
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
-		if m != nil {
-			t.Error("New() should return nil modem when no dialer provided")
+
+		_, err = modem.New(context.Background(), config)
+		if !errors.Is(err, modem.ErrSIMPinAttemptsLow) {
+			t.Errorf("expected ErrSIMPinAttemptsLow, got: %v", err)
 		}
 	})
 
-	t.Run("ErrNotInitialized on nil transport", func(t *testing.T) {
+	t.Run("Falls back to the Huawei PIN counter", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		mockTransport := modem.NewMockTransport(ctrl)
 		mockDialer := modem.NewMockDialer(ctrl)
-		mockDialer.EXPECT().Dial(gomock.Any()).Return(nil, nil)
+
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			NewMockSequence(mockTransport).
+				AT().
+				EchoOff().
+				VerboseErrors().
+				SimPinRequired().
+				Build(),
+			[]any{
+				mockTransport.EXPECT().Write([]byte("AT+QPINC=\"SC\"\r")).Return(14, nil),
+				mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+					return copy(p, "ERROR\r\n"), nil
+				}),
+				mockTransport.EXPECT().Write([]byte("AT^CPIN?\r")).Return(9, nil),
+				mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+					return copy(p, "^CPIN: SIM PIN,1,10,1,10,3\r\nOK\r\n"), nil
+				}),
+				mockTransport.EXPECT().Close(),
+			},
+		)...)
 
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithSimPIN("1234").
 			Build()
-
 		if err != nil {
-			t.Errorf("unexpected error from Build(): %v", err)
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
-		// This should create a modem with nil transport, putting it in "not initialized" state
 		_, err = modem.New(context.Background(), config)
-		if !errors.Is(err, modem.ErrNotInitialized) {
-			t.Errorf("expected ErrNotInitialized from New(), got: %v", err)
+		if !errors.Is(err, modem.ErrSIMPinAttemptsLow) {
+			t.Errorf("expected ErrSIMPinAttemptsLow, got: %v", err)
 		}
-
 	})
-}
 
-func TestModemClose(t *testing.T) {
-	t.Run("Closes underlying transport successfully", func(t *testing.T) {
+	t.Run("Reports initialization progress", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -166,117 +261,1497 @@ func TestModemClose(t *testing.T) {
 				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
 			},
 			initMockCalls(mockTransport),
-			[]any{
-				mockTransport.EXPECT().Close().Return(nil),
-			},
 		)...)
 
+		var reports []modem.InitProgress
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithInitProgress(func(p modem.InitProgress) { reports = append(reports, p) }).
 			Build()
-
 		if err != nil {
-			t.Errorf("unexpected error from Build(): %v", err)
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
 		m, err := modem.New(context.Background(), config)
 		if err != nil {
-			t.Errorf("unexpected error from New(): %v", err)
+			t.Fatalf("unexpected error from New(): %v", err)
 		}
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
 
-		if err := m.Close(); err != nil {
-			t.Errorf("unexpected error from Close(): %v", err)
+		var expected []modem.InitProgress
+		for _, step := range []modem.InitStep{
+			modem.InitStepHandshake,
+			modem.InitStepEchoOff,
+			modem.InitStepVerboseErrors,
+			modem.InitStepSIMStatus,
+			modem.InitStepTextMode,
+			modem.InitStepCharset,
+			modem.InitStepRegistration,
+		} {
+			expected = append(expected,
+				modem.InitProgress{Step: step, Status: modem.InitStarted},
+				modem.InitProgress{Step: step, Status: modem.InitCompleted},
+			)
+		}
+		if !slices.Equal(reports, expected) {
+			t.Errorf("unexpected progress reports:\nexpected: %v\ngot:      %v", expected, reports)
 		}
 	})
 
-	t.Run("Returns transport error on close failure", func(t *testing.T) {
+	t.Run("Reports the failing initialization step", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockTransport := modem.NewMockTransport(ctrl)
 		mockDialer := modem.NewMockDialer(ctrl)
 
-		closeError := errors.New("transport close failed")
 		gomock.InOrder(slices.Concat(
 			[]any{
 				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
 			},
-			initMockCalls(mockTransport),
+			NewMockSequence(mockTransport).
+				AT().
+				EchoOff().
+				VerboseErrors().
+				SimPinRequired().
+				Build(),
 			[]any{
-				mockTransport.EXPECT().Close().Return(closeError),
+				mockTransport.EXPECT().Close(),
 			},
 		)...)
 
+		var last modem.InitProgress
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithInitProgress(func(p modem.InitProgress) { last = p }).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		if _, err := modem.New(context.Background(), config); err == nil {
+			t.Fatal("expected New() to fail")
+		}
+		if last.Step != modem.InitStepSIMStatus || last.Status != modem.InitFailed {
+			t.Errorf("expected failed SIM status step, got: %+v", last)
+		}
+		if !errors.Is(last.Err, modem.ErrSIMPinRequired) {
+			t.Errorf("expected ErrSIMPinRequired in report, got: %v", last.Err)
+		}
+	})
+
+	t.Run("Waits for network registration", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		calls := NewMockSequence(mockTransport).
+			AT().
+			EchoOff().
+			VerboseErrors().
+			SimReady().
+			SMSTextMode().
+			Charset("GSM").
+			Registration("2"). // searching
+			Registration("5"). // roaming
+			Build()
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				calls,
+			)...,
+		)
+
+		clock := newFakeClock()
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithClock(clock).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		done := make(chan *modem.Modem, 1)
+		go func() {
+			m, err := modem.New(context.Background(), config)
+			if err != nil {
+				t.Errorf("unexpected error from New(): %v", err)
+			}
+			done <- m
+		}()
+
+		clock.WaitForWaiter()
+		clock.Advance(time.Second)
+		m := <-done
+		if m == nil {
+			return
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		if !m.Ready() {
+			t.Error("expected modem to be ready once roaming")
+		}
+	})
+
+	t.Run("Not ready when registration wait times out", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		calls := NewMockSequence(mockTransport).
+			AT().
+			EchoOff().
+			VerboseErrors().
+			SimReady().
+			SMSTextMode().
+			Charset("GSM").
+			Registration("2").
+			Registration("2").
+			Build()
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				calls,
+			)...,
+		)
+
+		clock := newFakeClock()
+		var last modem.InitProgress
+		metrics := &recordingMetrics{values: map[string]float64{}}
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithClock(clock).
+			WithMetrics(metrics).
+			WithRegistrationPolling(modem.PollConfig{Interval: time.Second, MaxRetries: 1}).
+			WithInitProgress(func(p modem.InitProgress) { last = p }).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		done := make(chan *modem.Modem, 1)
+		go func() {
+			m, err := modem.New(context.Background(), config)
+			if err != nil {
+				t.Errorf("registration timeout should not fail New(): %v", err)
+			}
+			done <- m
+		}()
+
+		// One tick for the single retry, one more to exhaust the retries
+		clock.WaitForWaiter()
+		var m *modem.Modem
+	wait:
+		for {
+			clock.Advance(time.Second)
+			select {
+			case m = <-done:
+				break wait
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		if m == nil {
+			return
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		if m.Ready() {
+			t.Error("expected modem not to be ready")
+		}
+		if last.Step != modem.InitStepRegistration || !errors.Is(last.Err, modem.ErrNotRegistered) {
+			t.Errorf("expected failed registration report, got: %+v", last)
+		}
+		if got, ok := metrics.lookup(modem.MetricReady); !ok || got != 0 {
+			t.Errorf("expected %s reported as 0, got %v (reported: %t)", modem.MetricReady, got, ok)
+		}
+	})
+
+	t.Run("Skips registration wait when disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("GSM").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithRegistrationWait(false).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		if !m.Ready() {
+			t.Error("expected modem to be ready without registration wait")
+		}
+	})
+
+	t.Run("ErrCharsetMismatch when modem keeps another character set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Build(),
+				[]any{
+					mockTransport.EXPECT().Write([]byte(`AT+CSCS="GSM"`+"\r")).Return(14, nil),
+					mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+						return copy(p, "OK\r\n"), nil
+					}),
+					mockTransport.EXPECT().Write([]byte("AT+CSCS?\r")).Return(9, nil),
+					mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+						return copy(p, "+CSCS: \"IRA\"\r\nOK\r\n"), nil
+					}),
+					mockTransport.EXPECT().Close(),
+				},
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		_, err = modem.New(context.Background(), config)
+		if !errors.Is(err, modem.ErrCharsetMismatch) {
+			t.Errorf("expected ErrCharsetMismatch, got: %v", err)
+		}
+	})
+
+	t.Run("Configures direct new message delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("GSM").
+					NewMessageIndication("AT+CNMI=2,2,0,0,0").
+					Registration("1").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithNewMessageMode(modem.NewMessageDirect).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		m.Close()
+	})
+
+	t.Run("Restores factory defaults before configuration", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					ProfileReset("AT&F").
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("GSM").
+					Registration("1").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithProfileReset(modem.ProfileFactory).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		m.Close()
+	})
+
+	t.Run("Saves the configured profile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("GSM").
+					NewMessageIndication("AT+CNMI=2,1,0,0,0").
+					SaveProfile().
+					Registration("1").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithNewMessageMode(modem.NewMessageStore).
+			WithSaveProfile(true).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+		mockTransport.EXPECT().Close().Return(nil)
+		m.Close()
+	})
+
+	t.Run("Drains stale input before initialization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		transport := &serialTransport{MockTransport: mockTransport}
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(transport, nil),
+					// Boot URC and a partial line, then the window passes quietly
+					mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+						return copy(p, "\r\nRDY\r\n\r\n+CPIN: READY\r\nOK\r\n+QI"), nil
+					}),
+					mockTransport.EXPECT().Read(gomock.Any()).Return(0, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithDrainWindow(50 * time.Millisecond).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		if _, err := modem.New(context.Background(), config); err != nil {
+			t.Fatalf("unexpected error from New(): %v", err)
+		}
+
+		expected := []string{"ResetInputBuffer()", "SetReadTimeout(50ms)", "SetReadTimeout(-1ns)"}
+		if !slices.Equal(transport.calls, expected) {
+			t.Errorf("expected %v, got: %v", expected, transport.calls)
+		}
+	})
+
+	t.Run("Dialer error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDialer := modem.NewMockDialer(ctrl)
+		mockDialer.EXPECT().Dial(gomock.Any()).Return(nil, errors.New("connection failed"))
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+
+		if err != nil {
+			t.Errorf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+
+		if err == nil {
+			t.Error("expected error from dialer failure")
+		}
+		if m != nil {
+			t.Error("New() should return nil modem when dialer fails")
+		}
+	})
+
+	t.Run("ErrNoDialer when no dialer provided", func(t *testing.T) {
+		m, err := modem.New(context.Background(), modem.Config{})
+		if !errors.Is(err, modem.ErrNoDialer) {
+			t.Errorf("expected ErrNoDialer from New(), got: %v", err)
+		}
+		if m != nil {
+			t.Error("New() should return nil modem when no dialer provided")
+		}
+	})
+
+	t.Run("ErrNotInitialized on nil transport", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDialer := modem.NewMockDialer(ctrl)
+		mockDialer.EXPECT().Dial(gomock.Any()).Return(nil, nil)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+
+		if err != nil {
+			t.Errorf("unexpected error from Build(): %v", err)
+		}
+
+		// This should create a modem with nil transport, putting it in "not initialized" state
+		_, err = modem.New(context.Background(), config)
+		if !errors.Is(err, modem.ErrNotInitialized) {
+			t.Errorf("expected ErrNotInitialized from New(), got: %v", err)
+		}
+
+	})
+}
+
+func TestModemClose(t *testing.T) {
+	t.Run("Closes underlying transport successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+			[]any{
+				mockTransport.EXPECT().Close().Return(nil),
+			},
+		)...)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+
+		if err != nil {
+			t.Errorf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Errorf("unexpected error from New(): %v", err)
+		}
+
+		if err := m.Close(); err != nil {
+			t.Errorf("unexpected error from Close(): %v", err)
+		}
+	})
+
+	t.Run("Returns transport error on close failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		closeError := errors.New("transport close failed")
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+			[]any{
+				mockTransport.EXPECT().Close().Return(closeError),
+			},
+		)...)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+
+		if err != nil {
+			t.Errorf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Errorf("unexpected error from New(): %v", err)
+		}
+
+		if err := m.Close(); err != closeError {
+			t.Errorf("expected transport error, got: %v", err)
+		}
+	})
+
+	t.Run("ErrAlreadyClosed on double close", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		// Set up successful initialization
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+			[]any{
+				mockTransport.EXPECT().Close().Return(nil),
+			},
+		)...)
+
+		// Expect Close to be called once successfully
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+
+		if err != nil {
+			t.Errorf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Errorf("unexpected error from New(): %v", err)
+		}
+		if m == nil {
+			t.Error("New() should return valid modem on success")
+		}
+
+		// First close should succeed
+		err = m.Close()
+		if err != nil {
+			t.Errorf("first close should succeed, got error: %v", err)
+		}
+
+		// Second close should return ErrAlreadyClosed
+		err = m.Close()
+		if err != modem.ErrAlreadyClosed {
+			t.Errorf("expected ErrAlreadyClosed on second close, got: %v", err)
+		}
+	})
+
+	t.Run("Fails commands in flight with ErrAlreadyClosed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+
+		// The modem never answers, the pending read fails once the
+		// transport is closed
+		written := make(chan struct{})
+		transportClosed := make(chan struct{})
+		mockTransport.EXPECT().Write([]byte("AT+CMGS=\"+1234567890\"\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(written)
+			return len(p), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-transportClosed
+			return 0, errors.New("port closed")
+		})
+		mockTransport.EXPECT().Close().DoAndReturn(func() error {
+			close(transportClosed)
+			return nil
+		})
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		sendErr := make(chan error, 1)
+		go func() {
+			sendErr <- m.SendSMS(ctx, "+1234567890", "Hello")
+		}()
+
+		<-written
+		if err := m.Close(); err != nil {
+			t.Errorf("unexpected error from Close(): %v", err)
+		}
+
+		if err := <-sendErr; !errors.Is(err, modem.ErrAlreadyClosed) {
+			t.Errorf("expected ErrAlreadyClosed, got: %v", err)
+		}
+		if err := <-loopDone; err != nil {
+			t.Errorf("expected Loop to stop without error, got: %v", err)
+		}
+	})
+}
+
+func TestModemLoop(t *testing.T) {
+	t.Run("Starts and stops on EOF", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// This test verifies Loop handles normal transport I/O
+		allowEOF := make(chan struct{})
+
+		// Loop should read continuously until context cancellation or EOF
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-allowEOF
+			return 0, io.EOF
+		})
+		mockTransport.EXPECT().Close().Return(nil)
+
+		// Start Loop in goroutine and verify it runs until EOF
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		// Signal EOF and wait for Loop to complete
+		close(allowEOF)
+		err = <-loopDone
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.Errorf("expected Loop to handle EOF gracefully, got: %v", err)
+		}
+	})
+
+	t.Run("Dispatch URCs to the designated channel", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// Coordinate reads to ensure URC is processed before EOF
+		allowEOF := make(chan struct{})
+
+		// First read returns a URC, second returns EOF
+		gomock.InOrder(
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "+CMTI: \"SM\",1\r\n"), nil // New SMS URC
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				<-allowEOF
+				return 0, io.EOF
+			}),
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		// Start Loop
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		// Check that URC is received on URC channel
+		select {
+		case urc := <-m.URC():
+			if !strings.Contains(urc, "+CMTI:") {
+				t.Errorf("expected URC to contain +CMTI:, got: %q", urc)
+			}
+		case <-time.After(time.Second):
+			t.Error("expected URC to be received within timeout")
+		}
+
+		// Signal EOF and wait for Loop to finish
+		close(allowEOF)
+		err = <-loopDone
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			t.Errorf("expected Loop to handle EOF gracefully, got: %v", err)
+		}
+	})
+
+	t.Run("Dispatches vendor URCs known to the classifier", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithClassifier(at.PrefixClassifier{
+				Prefixes: map[string]at.ResponseType{"^RSSI:": at.TypeURC},
+			}).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The vendor URC arrives while a command is in flight, it must not
+		// be taken as part of the response
+		eof := expectExchanges(mockTransport, Exchange{"AT+CPIN?\r", "^RSSI: 17\r\n+CPIN: READY\r\n\r\nOK\r\n"})
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		if _, err := m.SIMStatus(ctx); err != nil {
+			t.Errorf("unexpected error from SIMStatus(): %v", err)
+		}
+		select {
+		case urc := <-m.URC():
+			if urc != "^RSSI: 17" {
+				t.Errorf("expected ^RSSI URC, got: %q", urc)
+			}
+		case <-time.After(time.Second):
+			t.Error("expected URC to be received within timeout")
+		}
+
+		eof()
+		if err := <-loopDone; !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got: %v", err)
+		}
+	})
+
+	t.Run("Dispatches +CMT header and body as one URC", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The body looks like a final response and starts like a prompt,
+		// neither must be interpreted
+		allowEOF := make(chan struct{})
+		gomock.InOrder(
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"\r\nOK\r\n"), nil
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "+CMT: \"+1234567890\",,\"24/01/15,10:31:00+08\"\r\n> quoted\r\n"), nil
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				<-allowEOF
+				return 0, io.EOF
+			}),
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		for _, expected := range []string{
+			"+CMT: \"+1234567890\",,\"24/01/15,10:30:00+08\"\nOK",
+			"+CMT: \"+1234567890\",,\"24/01/15,10:31:00+08\"\n> quoted",
+		} {
+			select {
+			case urc := <-m.URC():
+				if urc != expected {
+					t.Errorf("expected URC %q, got: %q", expected, urc)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected URC to be received within timeout")
+			}
+		}
+
+		close(allowEOF)
+		<-loopDone
+	})
+
+	t.Run("Dispatches configured multi-line URCs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithURCFrames(at.URCFrame{Prefix: "+CUSD:", UntilBlank: true}).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		allowEOF := make(chan struct{})
+		gomock.InOrder(
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "\r\n+CUSD: 0,\"Balance\r\n12.50 EUR\",15\r\n\r\nRING\r\n"), nil
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				<-allowEOF
+				return 0, io.EOF
+			}),
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		for _, expected := range []string{"+CUSD: 0,\"Balance\n12.50 EUR\",15", "RING"} {
+			select {
+			case urc := <-m.URC():
+				if urc != expected {
+					t.Errorf("expected URC %q, got: %q", expected, urc)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected URC to be received within timeout")
+			}
+		}
+
+		close(allowEOF)
+		<-loopDone
+	})
+
+	t.Run("Holds status polls during text entry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The modem answers each command once it was written. The prompt is
+		// held back until the status poll is queued.
+		responses := make(chan string, 1)
+		answer := func(resp string) func(p []byte) (int, error) {
+			return func(p []byte) (int, error) {
+				responses <- resp
+				return len(p), nil
+			}
+		}
+		cmgsWritten := make(chan struct{})
+		gomock.InOrder(
+			mockTransport.EXPECT().Write([]byte("AT+CMGS=\"+1234567890\"\r")).DoAndReturn(func(p []byte) (int, error) {
+				defer close(cmgsWritten)
+				return answer("> ")(p)
+			}),
+			mockTransport.EXPECT().Write([]byte("Alarm\x1a\r")).DoAndReturn(answer("+CMGS: 1\r\nOK\r\n")),
+			mockTransport.EXPECT().Write([]byte("AT+CPIN?\r")).DoAndReturn(answer("+CPIN: READY\r\nOK\r\n")),
+		)
+		allowPrompt := make(chan struct{})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-allowPrompt
+			return copy(p, <-responses), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			return copy(p, <-responses), nil
+		}).Times(2)
+		eof := expectExchanges(mockTransport)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		sent := make(chan error, 1)
+		go func() {
+			sent <- m.SendSMS(ctx, "+1234567890", "Alarm")
+		}()
+		<-cmgsWritten
+
+		poll := newQueueSignal(t)
+		polled := make(chan error, 1)
+		go func() {
+			_, err := m.SIMStatus(poll)
+			polled <- err
+		}()
+		<-poll.queued
+
+		close(allowPrompt)
+		if err := <-sent; err != nil {
+			t.Errorf("unexpected send error: %v", err)
+		}
+		if err := <-polled; err != nil {
+			t.Errorf("unexpected poll error: %v", err)
+		}
+		eof()
+	})
+
+	t.Run("Lets a status poll through after a burst of messages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The answer to the first poll is held back until six messages and
+		// another poll are queued. Four messages go first, then the poll.
+		const messages = 6
+		responses := make(chan string, 1)
+		answer := func(resp string) func(p []byte) (int, error) {
+			return func(p []byte) (int, error) {
+				responses <- resp
+				return len(p), nil
+			}
+		}
+		csqWritten := make(chan struct{})
+		writes := []any{
+			mockTransport.EXPECT().Write([]byte("AT+CSQ\r")).DoAndReturn(func(p []byte) (int, error) {
+				defer close(csqWritten)
+				return answer("+CSQ: 20,99\r\nOK\r\n")(p)
+			}),
+		}
+		for i := range messages {
+			if i == 4 {
+				writes = append(writes, mockTransport.EXPECT().Write([]byte("AT+CPIN?\r")).DoAndReturn(answer("+CPIN: READY\r\nOK\r\n")))
+			}
+			writes = append(writes, mockTransport.EXPECT().Write([]byte("AT+CMGD=1\r")).DoAndReturn(answer("OK\r\n")))
+		}
+		gomock.InOrder(writes...)
+		release := make(chan struct{})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-release
+			return copy(p, <-responses), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			return copy(p, <-responses), nil
+		}).Times(messages + 1)
+		eof := expectExchanges(mockTransport)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		errs := make(chan error, messages+2)
+		go func() {
+			_, err := m.ExecTelemetry(ctx, "AT+CSQ")
+			errs <- err
+		}()
+		<-csqWritten
+
+		for range messages {
+			queue := newQueueSignal(t)
+			go func() {
+				_, err := m.Exec(queue, "AT+CMGD=1")
+				errs <- err
+			}()
+			<-queue.queued
+		}
+		poll := newQueueSignal(t)
+		go func() {
+			_, err := m.ExecTelemetry(poll, "AT+CPIN?")
+			errs <- err
+		}()
+		<-poll.queued
+
+		close(release)
+		for range messages + 2 {
+			if err := <-errs; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		eof()
+	})
+
+	t.Run("Reports loop events to diagnostics", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		// Called from the Loop only, read after it returned
+		var reported []modem.Diagnostic
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithDiagnostics(func(d modem.Diagnostic) {
+				reported = append(reported, d)
+			}).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// A complete response nobody asked for
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			return copy(p, "\r\n+CSQ: 15,99\r\n\r\nOK\r\n\r\nOK\r\n"), nil
+		})
+		eof := expectExchanges(mockTransport)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		eof()
+		if err := <-loopDone; !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got: %v", err)
+		}
+
+		expected := []modem.Diagnostic{
+			{Event: modem.EventOrphanedData, Token: "+CSQ: 15,99", Count: 1},
+			{Event: modem.EventOrphanedResponse, Token: "OK", Count: 1},
+			{Event: modem.EventOrphanedResponse, Token: "OK", Count: 2},
+		}
+		if !slices.Equal(reported, expected) {
+			t.Errorf("expected diagnostics %v, got: %v", expected, reported)
+		}
+		counts := m.Diagnostics()
+		if counts[modem.EventOrphanedData] != 1 || counts[modem.EventOrphanedResponse] != 2 || len(counts) != 2 {
+			t.Errorf("unexpected counts: %v", counts)
+		}
+	})
+
+	t.Run("Counts orphaned responses and resyncs", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		var logs bytes.Buffer
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithLogger(slog.New(slog.NewTextHandler(&logs, nil))).
+			WithOrphanResync(2).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// Two late answers nobody waits for, then the answer to the ping
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			return copy(p, "\r\nOK\r\n\r\nERROR\r\n"), nil
+		})
+		eof := expectExchanges(mockTransport, Exchange{Command: "AT\r", Response: "\r\nOK\r\n"})
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		eof()
+		if err := <-loopDone; !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got: %v", err)
+		}
+
+		if got := m.OrphanedResponses(); got != 2 {
+			t.Errorf("expected 2 orphaned responses, got: %d", got)
+		}
+		for _, msg := range []string{"orphaned final response", "modem resynchronized"} {
+			if !strings.Contains(logs.String(), msg) {
+				t.Errorf("expected %q to be logged, got:\n%s", msg, logs.String())
+			}
+		}
+	})
+
+	t.Run("Discards late response of a timed out command", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithMinSendInterval(0).
 			Build()
-
 		if err != nil {
-			t.Errorf("unexpected error from Build(): %v", err)
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
-		m, err := modem.New(context.Background(), config)
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
 		if err != nil {
-			t.Errorf("unexpected error from New(): %v", err)
+			t.Fatalf("failed to create modem: %v", err)
 		}
+		defer m.Close()
 
-		if err := m.Close(); err != closeError {
-			t.Errorf("expected transport error, got: %v", err)
+		// The first AT+CMGS is only answered after it timed out, together
+		// with the resync ping written before the next command
+		eof := expectExchanges(mockTransport,
+			Exchange{Command: "AT+CMGS=\"+1234567890\"\r"},
+			Exchange{Command: "AT\r", Response: "\r\n+CMS ERROR: 500\r\n\r\nOK\r\n"},
+			Exchange{Command: "AT+CMGS=\"+1234567890\"\r", Response: "\r\n> "},
+			Exchange{Command: "Hello\x1a\r", Response: "\r\n+CMGS: 7\r\n\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if err := m.SendSMS(timeoutCtx, "+1234567890", "Hello"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected context.DeadlineExceeded, got: %v", err)
 		}
+
+		if err := m.SendSMS(ctx, "+1234567890", "Hello"); err != nil {
+			t.Errorf("expected second send to succeed, got: %v", err)
+		}
+		if got := m.OrphanedResponses(); got != 0 {
+			t.Errorf("expected no orphaned responses, got: %d", got)
+		}
+
+		eof()
+		<-loopDone
 	})
 
-	t.Run("ErrAlreadyClosed on double close", func(t *testing.T) {
+	t.Run("Abort escapes a late prompt and resyncs", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockTransport := modem.NewMockTransport(ctrl)
 		mockDialer := modem.NewMockDialer(ctrl)
 
-		// Set up successful initialization
-		gomock.InOrder(slices.Concat(
-			[]any{
-				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
-			},
-			initMockCalls(mockTransport),
-			[]any{
-				mockTransport.EXPECT().Close().Return(nil),
-			},
-		)...)
-
-		// Expect Close to be called once successfully
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
 
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
 			Build()
-
 		if err != nil {
-			t.Errorf("unexpected error from Build(): %v", err)
+			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
-		m, err := modem.New(context.Background(), config)
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
 		if err != nil {
-			t.Errorf("unexpected error from New(): %v", err)
-		}
-		if m == nil {
-			t.Error("New() should return valid modem on success")
+			t.Fatalf("failed to create modem: %v", err)
 		}
+		defer m.Close()
 
-		// First close should succeed
-		err = m.Close()
-		if err != nil {
-			t.Errorf("first close should succeed, got error: %v", err)
+		// The prompt for the aborted AT+CMGS only arrives after the resync
+		// ping, which the modem takes as message text. The Loop escapes the
+		// text entry and pings again.
+		cmgsWritten := make(chan struct{})
+		mockTransport.EXPECT().Write([]byte("AT+CMGS=\"+1234567890\"\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(cmgsWritten)
+			return len(p), nil
+		})
+		eof := expectExchanges(mockTransport,
+			Exchange{Command: "AT\r", Response: "\r\n> "},
+			Exchange{Command: "\x1b"},
+			Exchange{Command: "AT\r", Response: "\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		sendErr := make(chan error, 1)
+		go func() {
+			sendErr <- m.SendSMS(ctx, "+1234567890", "Hello")
+		}()
+
+		<-cmgsWritten
+		if err := m.Abort(ctx); err != nil {
+			t.Fatalf("unexpected error from Abort(): %v", err)
+		}
+		if err := <-sendErr; !errors.Is(err, modem.ErrAborted) {
+			t.Errorf("expected ErrAborted, got: %v", err)
 		}
 
-		// Second close should return ErrAlreadyClosed
-		err = m.Close()
-		if err != modem.ErrAlreadyClosed {
-			t.Errorf("expected ErrAlreadyClosed on second close, got: %v", err)
+		eof()
+		<-loopDone
+
+		if got := m.OrphanedResponses(); got != 0 {
+			t.Errorf("expected no orphaned responses, got: %d", got)
 		}
 	})
-}
 
-func TestModemLoop(t *testing.T) {
-	t.Run("Starts and stops on EOF", func(t *testing.T) {
+	t.Run("Pings the modem when idle", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -292,48 +1767,60 @@ func TestModemLoop(t *testing.T) {
 			)...,
 		)
 
+		clock := newFakeClock()
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithClock(clock).
+			WithKeepalive(time.Minute).
 			Build()
 		if err != nil {
 			t.Fatalf("unexpected error from Build(): %v", err)
 		}
 
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel()
-
+		ctx := context.Background()
 		m, err := modem.New(ctx, config)
 		if err != nil {
 			t.Fatalf("failed to create modem: %v", err)
 		}
 		defer m.Close()
 
-		// This test verifies Loop handles normal transport I/O
-		allowEOF := make(chan struct{})
-
-		// Loop should read continuously until context cancellation or EOF
+		pinged := make(chan struct{})
+		mockTransport.EXPECT().Write([]byte("AT\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(pinged)
+			return len(p), nil
+		})
 		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
-			<-allowEOF
-			return 0, io.EOF
+			<-pinged
+			return copy(p, "OK\r\n"), nil
 		})
+		eof := expectExchanges(mockTransport, Exchange{"AT+CPIN?\r", "+CPIN: READY\r\n\r\nOK\r\n"})
 		mockTransport.EXPECT().Close().Return(nil)
 
-		// Start Loop in goroutine and verify it runs until EOF
 		loopDone := make(chan error, 1)
 		go func() {
 			loopDone <- m.Loop(ctx)
 		}()
 
-		// Signal EOF and wait for Loop to complete
-		close(allowEOF)
-		err = <-loopDone
+		clock.WaitForWaiter()
+		clock.Advance(time.Minute)
+		<-pinged
 
-		if err != nil && !errors.Is(err, io.EOF) {
-			t.Errorf("expected Loop to handle EOF gracefully, got: %v", err)
+		// The status poll is only written once the ping was answered
+		state, err := m.SIMStatus(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error from SIMStatus(): %v", err)
+		}
+		if state != modem.SIMReady {
+			t.Errorf("expected SIMReady, got: %v", state)
+		}
+
+		eof()
+		if err := <-loopDone; !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF, got: %v", err)
 		}
 	})
 
-	t.Run("Dispatch URCs to the designated channel", func(t *testing.T) {
+	t.Run("ErrKeepaliveTimeout when the ping is not answered", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
@@ -349,8 +1836,11 @@ func TestModemLoop(t *testing.T) {
 			)...,
 		)
 
+		clock := newFakeClock()
 		config, err := modem.NewConfigBuilder().
 			WithDialer(mockDialer).
+			WithClock(clock).
+			WithKeepalive(time.Minute).
 			Build()
 		if err != nil {
 			t.Fatalf("unexpected error from Build(): %v", err)
@@ -363,44 +1853,27 @@ func TestModemLoop(t *testing.T) {
 		}
 		defer m.Close()
 
-		// Coordinate reads to ensure URC is processed before EOF
-		allowEOF := make(chan struct{})
-
-		// First read returns a URC, second returns EOF
-		gomock.InOrder(
-			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
-				return copy(p, "+CMTI: \"SM\",1\r\n"), nil // New SMS URC
-			}),
-			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
-				<-allowEOF
-				return 0, io.EOF
-			}),
-		)
+		eof := expectExchanges(mockTransport, Exchange{Command: "AT\r"})
 		mockTransport.EXPECT().Close().Return(nil)
 
-		// Start Loop
 		loopDone := make(chan error, 1)
 		go func() {
 			loopDone <- m.Loop(ctx)
 		}()
 
-		// Check that URC is received on URC channel
-		select {
-		case urc := <-m.URC():
-			if !strings.Contains(urc, "+CMTI:") {
-				t.Errorf("expected URC to contain +CMTI:, got: %q", urc)
-			}
-		case <-time.After(time.Second):
-			t.Error("expected URC to be received within timeout")
-		}
-
-		// Signal EOF and wait for Loop to finish
-		close(allowEOF)
-		err = <-loopDone
+		// Idle for a minute, then the ping times out
+		clock.WaitForWaiter()
+		clock.Advance(time.Minute)
+		clock.WaitForWaiter()
+		clock.Advance(5 * time.Second)
 
-		if err != nil && !errors.Is(err, io.EOF) {
-			t.Errorf("expected Loop to handle EOF gracefully, got: %v", err)
+		if err := <-loopDone; !errors.Is(err, modem.ErrKeepaliveTimeout) {
+			t.Errorf("expected ErrKeepaliveTimeout, got: %v", err)
+		}
+		if !errors.Is(m.Err(), modem.ErrKeepaliveTimeout) {
+			t.Errorf("expected Err() to report ErrKeepaliveTimeout, got: %v", m.Err())
 		}
+		eof()
 	})
 
 	t.Run("Exits gracefully on context cancellation", func(t *testing.T) {
@@ -541,10 +2014,12 @@ func TestModemLoop(t *testing.T) {
 		defer m.Close()
 
 		// Set up minimal expectations for first Loop
+		readStarted := make(chan struct{})
 		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			close(readStarted)
 			<-ctx.Done()
 			return 0, ctx.Err()
-		}).AnyTimes()
+		})
 		mockTransport.EXPECT().Close().Return(nil)
 
 		// Start first Loop in background
@@ -553,8 +2028,8 @@ func TestModemLoop(t *testing.T) {
 			loopDone <- m.Loop(ctx)
 		}()
 
-		// Give first Loop time to start and set loopRunning flag
-		time.Sleep(10 * time.Millisecond)
+		// The reader only starts once the first Loop set its loopRunning flag
+		<-readStarted
 
 		// Try to start second Loop - should fail immediately
 		err = m.Loop(ctx)
@@ -566,4 +2041,127 @@ func TestModemLoop(t *testing.T) {
 		cancel()
 		<-loopDone
 	})
+	t.Run("Restarts reader after a panic", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		// Reader restarts are limited on their own, not by the retries
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMaxRetries(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		allowEOF := make(chan struct{})
+		gomock.InOrder(
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				panic("driver bug")
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return copy(p, "+CMTI: \"SM\",1\r\n"), nil
+			}),
+			mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				<-allowEOF
+				return 0, io.EOF
+			}),
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		loopDone := make(chan error, 1)
+		go func() {
+			loopDone <- m.Loop(ctx)
+		}()
+
+		// The restarted reader must still deliver URCs
+		select {
+		case urc := <-m.URC():
+			if !strings.Contains(urc, "+CMTI:") {
+				t.Errorf("expected URC to contain +CMTI:, got: %q", urc)
+			}
+		case <-time.After(time.Second):
+			t.Error("expected URC to be received after reader restart")
+		}
+
+		close(allowEOF)
+		if err := <-loopDone; !errors.Is(err, io.EOF) {
+			t.Errorf("expected Loop to stop on EOF, got: %v", err)
+		}
+		if !errors.Is(m.Err(), io.EOF) {
+			t.Errorf("expected Err() to report EOF, got: %v", m.Err())
+		}
+	})
+
+	t.Run("PanicError when reader restarts are exhausted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithReaderRestarts(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			panic("driver bug")
+		})
+		mockTransport.EXPECT().Close().Return(nil)
+
+		if m.Err() != nil {
+			t.Errorf("expected no error before Loop ran, got: %v", m.Err())
+		}
+
+		err = m.Loop(ctx)
+		var panicErr *modem.PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected PanicError, got: %v", err)
+		}
+		if panicErr.Goroutine != "reader" || panicErr.Value != "driver bug" {
+			t.Errorf("unexpected panic details: %+v", panicErr)
+		}
+		if !errors.As(m.Err(), &panicErr) {
+			t.Errorf("expected Err() to report the panic, got: %v", m.Err())
+		}
+	})
 }
@@ -0,0 +1,92 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// SignalQuality queries the received signal strength and bit error rate
+// (AT+CSQ).
+func (m *Modem) SignalQuality(ctx context.Context) (SignalQuality, error) {
+	resp, err := m.execTelemetry(ctx, at.CmdSignal)
+	if err != nil {
+		return SignalQuality{}, fmt.Errorf("query signal quality: %w", err)
+	}
+	csq, err := at.ParseCSQ(resp)
+	if err != nil {
+		return SignalQuality{}, fmt.Errorf("query signal quality: %w", err)
+	}
+	return SignalQuality{RSSI: csq.RSSI, BER: csq.BER}, nil
+}
+
+// NetworkStatus queries the network registration (AT+CREG?) and, if
+// registered, the name of the network (AT+COPS?).
+func (m *Modem) NetworkStatus(ctx context.Context) (NetworkStatus, error) {
+	resp, err := m.execTelemetry(ctx, at.CmdRegStatus)
+	if err != nil {
+		return NetworkStatus{}, fmt.Errorf("query registration: %w", err)
+	}
+	creg, err := at.ParseCREG(resp)
+	if err != nil {
+		return NetworkStatus{}, fmt.Errorf("query registration: %w", err)
+	}
+	status := NetworkStatus{
+		Registration: RegistrationStatus(creg.Stat),
+		LAC:          creg.LAC,
+		CellID:       creg.CellID,
+	}
+	if !status.Registration.Registered() {
+		return status, nil
+	}
+
+	resp, err = m.execTelemetry(ctx, at.CmdOperator)
+	if err != nil {
+		return NetworkStatus{}, fmt.Errorf("query operator: %w", err)
+	}
+	cops, err := at.ParseCOPS(resp)
+	if err != nil {
+		return NetworkStatus{}, fmt.Errorf("query operator: %w", err)
+	}
+	status.Operator = cops.Operator
+	return status, nil
+}
+
+// DeviceInfo queries the manufacturer, model, firmware revision and IMEI
+// of the modem.
+func (m *Modem) DeviceInfo(ctx context.Context) (DeviceInfo, error) {
+	var info DeviceInfo
+	for _, query := range []struct {
+		cmd   string
+		field *string
+	}{
+		{cmd: at.CmdManufacturer, field: &info.Manufacturer},
+		{cmd: at.CmdModel, field: &info.Model},
+		{cmd: at.CmdRevision, field: &info.Revision},
+		{cmd: at.CmdIMEI, field: &info.IMEI},
+	} {
+		resp, err := m.execTelemetry(ctx, query.cmd)
+		if err != nil {
+			return DeviceInfo{}, fmt.Errorf("query device info: %w", err)
+		}
+		*query.field = identification(resp, query.cmd)
+	}
+	return info, nil
+}
+
+// identification returns the value of the response to an identification
+// command such as AT+CGMI. Most modems answer with the bare value, some
+// prefix it with the command name, e.g. "+CGMI: Quectel".
+func identification(resp, cmd string) string {
+	prefix := strings.TrimPrefix(cmd, "AT") + ":"
+	for line := range strings.Lines(resp) {
+		line = strings.TrimSpace(line)
+		if line == "" || line == at.OK {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, prefix)), `"`)
+	}
+	return ""
+}
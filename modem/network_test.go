@@ -0,0 +1,150 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestNetworkQueries(t *testing.T) {
+	newModem := func(t *testing.T, ctrl *gomock.Controller) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	t.Run("Signal quality", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CSQ\r", "+CSQ: 15,99\r\n\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		quality, err := m.SignalQuality(ctx)
+		eof()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if quality != (modem.SignalQuality{RSSI: 15, BER: 99}) || quality.DBm() != -83 {
+			t.Errorf("expected RSSI 15 (-83 dBm), got %+v (%d dBm)", quality, quality.DBm())
+		}
+	})
+
+	t.Run("Network status", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			exchanges []Exchange
+			expected  modem.NetworkStatus
+		}{
+			{
+				name: "Roaming",
+				exchanges: []Exchange{
+					{"AT+CREG?\r", "+CREG: 2,5,\"1A2B\",\"01C3D4E5\"\r\n\r\nOK\r\n"},
+					{"AT+COPS?\r", "+COPS: 0,0,\"Vodafone GR\",7\r\n\r\nOK\r\n"},
+				},
+				expected: modem.NetworkStatus{
+					Registration: modem.RegistrationRoaming,
+					Operator:     "Vodafone GR",
+					LAC:          "1A2B",
+					CellID:       "01C3D4E5",
+				},
+			},
+			{
+				name: "Searching skips the operator",
+				exchanges: []Exchange{
+					{"AT+CREG?\r", "+CREG: 0,2\r\n\r\nOK\r\n"},
+				},
+				expected: modem.NetworkStatus{Registration: modem.RegistrationSearching},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				m, mockTransport := newModem(t, ctrl)
+				defer m.Close()
+
+				eof := expectExchanges(mockTransport, tt.exchanges...)
+				mockTransport.EXPECT().Close().Return(nil)
+
+				ctx := context.Background()
+				go m.Loop(ctx)
+
+				status, err := m.NetworkStatus(ctx)
+				eof()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if status != tt.expected {
+					t.Errorf("expected %+v, got %+v", tt.expected, status)
+				}
+			})
+		}
+	})
+
+	t.Run("Device info", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CGMI\r", "Quectel\r\n\r\nOK\r\n"},
+			Exchange{"AT+CGMM\r", "EC25\r\n\r\nOK\r\n"},
+			Exchange{"AT+CGMR\r", "+CGMR: EC25EFAR06A06M4G\r\n\r\nOK\r\n"},
+			Exchange{"AT+CGSN\r", "866758040000000\r\n\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		info, err := m.DeviceInfo(ctx)
+		eof()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := modem.DeviceInfo{
+			Manufacturer: "Quectel",
+			Model:        "EC25",
+			Revision:     "EC25EFAR06A06M4G",
+			IMEI:         "866758040000000",
+		}
+		if info != expected {
+			t.Errorf("expected %+v, got %+v", expected, info)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package modem
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pacer spaces out message submissions by a minimum interval. Each caller
+// reserves the next free slot, so concurrent senders are released one
+// interval apart in the order they arrived instead of all at once.
+type pacer struct {
+	clock    Clock
+	interval time.Duration
+
+	mu sync.Mutex
+	// next is the earliest time the next submission may start
+	next time.Time
+}
+
+func newPacer(clock Clock, interval time.Duration) *pacer {
+	return &pacer{clock: clock, interval: interval}
+}
+
+// wait blocks until the caller's slot is due or ctx is done. A slot given
+// up because of ctx is released again if no later caller reserved one.
+func (p *pacer) wait(ctx context.Context) error {
+	if p.interval <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := p.clock.Now()
+	slot := p.next
+	if slot.Before(now) {
+		slot = now
+	}
+	p.next = slot.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := p.clock.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		p.mu.Lock()
+		if p.next.Equal(slot.Add(p.interval)) {
+			p.next = slot
+		}
+		p.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// remaining returns how long a submission starting now would have to wait.
+func (p *pacer) remaining() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return max(p.next.Sub(p.clock.Now()), 0)
+}
@@ -0,0 +1,91 @@
+package modem
+
+import (
+	"context"
+	"sync"
+)
+
+// gate holds callers while it is closed. It starts open.
+type gate struct {
+	mu sync.Mutex
+	// opened is closed when the gate opens again, nil while it is open
+	opened chan struct{}
+}
+
+// close makes subsequent wait calls block and reports whether the gate was
+// open.
+func (g *gate) close() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.opened != nil {
+		return false
+	}
+	g.opened = make(chan struct{})
+	return true
+}
+
+// open releases all waiting callers and reports whether the gate was
+// closed.
+func (g *gate) open() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.opened == nil {
+		return false
+	}
+	close(g.opened)
+	g.opened = nil
+	return true
+}
+
+// isClosed reports whether callers are currently held.
+func (g *gate) isClosed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.opened != nil
+}
+
+// wait blocks while the gate is closed or until ctx is done.
+func (g *gate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	opened := g.opened
+	g.mu.Unlock()
+	if opened == nil {
+		return nil
+	}
+
+	select {
+	case <-opened:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pause holds all subsequent SendSMS and SendBinarySMS calls until Resume
+// is called, e.g. during carrier maintenance or when the SIM is close to
+// its quota. Held calls are not failed, they block until the modem is
+// resumed or their context is done. This includes the calls already waiting
+// for their send interval, only a message already being submitted when
+// Pause is called is still sent. Pausing a paused modem has no effect.
+func (m *Modem) Pause() {
+	if m.sending.close() {
+		m.config.logger.Info("sending paused")
+		m.config.metrics.SetGauge(MetricPaused, 1)
+		m.recordLifecycle(LifecyclePaused, "")
+	}
+}
+
+// Resume releases the calls held by Pause. Resuming a modem that is not
+// paused has no effect.
+func (m *Modem) Resume() {
+	if m.sending.open() {
+		m.config.logger.Info("sending resumed")
+		m.config.metrics.SetGauge(MetricPaused, 0)
+		m.recordLifecycle(LifecycleResumed, "")
+	}
+}
+
+// Paused reports whether sending is paused.
+func (m *Modem) Paused() bool {
+	return m.sending.isClosed()
+}
@@ -0,0 +1,93 @@
+package modem
+
+// InitStep identifies a step of the modem initialization sequence. The
+// values are human readable, so they can be logged or reported as is.
+type InitStep string
+
+const (
+	InitStepDrain         InitStep = "drain stale input"
+	InitStepHandshake     InitStep = "handshake"
+	InitStepProfileReset  InitStep = "reset modem profile"
+	InitStepEchoOff       InitStep = "disable echo"
+	InitStepVerboseErrors InitStep = "enable verbose errors"
+	InitStepSIMStatus     InitStep = "check SIM status"
+	InitStepSIMPIN        InitStep = "enter SIM PIN"
+	InitStepSIMReady      InitStep = "wait for SIM ready"
+	InitStepTextMode      InitStep = "select SMS text mode"
+	InitStepCharset       InitStep = "select character set"
+	InitStepNewMessage    InitStep = "configure new message indication"
+	InitStepSaveProfile   InitStep = "save modem profile"
+	InitStepRegistration  InitStep = "wait for network registration"
+)
+
+// InitStatus describes the state of an initialization step.
+type InitStatus int
+
+const (
+	// InitStarted is reported when a step begins
+	InitStarted InitStatus = iota
+	// InitCompleted is reported when a step finished successfully
+	InitCompleted
+	// InitFailed is reported when a step failed. Failures abort the
+	// initialization, except for InitStepRegistration where the modem is
+	// returned but not Ready.
+	InitFailed
+)
+
+func (s InitStatus) String() string {
+	switch s {
+	case InitStarted:
+		return "started"
+	case InitCompleted:
+		return "completed"
+	case InitFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// InitProgress is reported to the callback set with
+// ConfigBuilder.WithInitProgress while New initializes the modem.
+type InitProgress struct {
+	// Step is the initialization step the report refers to
+	Step InitStep
+	// Status is the state of the step
+	Status InitStatus
+	// Err is the reason of the failure when Status is InitFailed
+	Err error
+}
+
+// initTracker reports the progress of the initialization sequence to the
+// configured callback, if any.
+type initTracker struct {
+	report  func(InitProgress)
+	current InitStep
+}
+
+// begin completes the current step, if any, and starts the given one.
+func (t *initTracker) begin(step InitStep) {
+	t.complete()
+	t.current = step
+	t.send(InitProgress{Step: step, Status: InitStarted})
+}
+
+// complete marks the current step as completed.
+func (t *initTracker) complete() {
+	if t.current != "" {
+		t.send(InitProgress{Step: t.current, Status: InitCompleted})
+		t.current = ""
+	}
+}
+
+// fail marks the current step as failed with err.
+func (t *initTracker) fail(err error) {
+	t.send(InitProgress{Step: t.current, Status: InitFailed, Err: err})
+	t.current = ""
+}
+
+func (t *initTracker) send(p InitProgress) {
+	if t.report != nil {
+		t.report(p)
+	}
+}
@@ -0,0 +1,161 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// SIMState is the state of the SIM card as reported by AT+CPIN?.
+type SIMState int
+
+const (
+	// SIMUnknown is a state the modem reported that is not known here, such
+	// as a PH-SIM PIN or PIN2 request
+	SIMUnknown SIMState = iota
+	// SIMReady means the SIM is unlocked and usable
+	SIMReady
+	// SIMPINRequired means the SIM waits for its PIN
+	SIMPINRequired
+	// SIMPUKRequired means the SIM is blocked after too many wrong PINs and
+	// waits for its PUK
+	SIMPUKRequired
+	// SIMNotInserted means no SIM card was detected
+	SIMNotInserted
+	// SIMBusy means the SIM is still starting up and should be queried again
+	SIMBusy
+)
+
+func (s SIMState) String() string {
+	switch s {
+	case SIMReady:
+		return "ready"
+	case SIMPINRequired:
+		return "PIN required"
+	case SIMPUKRequired:
+		return "PUK required"
+	case SIMNotInserted:
+		return "not inserted"
+	case SIMBusy:
+		return "busy"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the state by its name, so that it serializes as
+// e.g. "PIN required" in JSON.
+func (s SIMState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a state name as encoded by MarshalText. Names that
+// are not known decode to SIMUnknown.
+func (s *SIMState) UnmarshalText(text []byte) error {
+	for state := SIMUnknown; state <= SIMBusy; state++ {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	*s = SIMUnknown
+	return nil
+}
+
+// SIMStatus queries the state of the SIM card. Modems report a missing or
+// busy SIM as an error rather than a +CPIN response, these are returned as
+// SIMNotInserted and SIMBusy without an error.
+func (m *Modem) SIMStatus(ctx context.Context) (SIMState, error) {
+	state, err := parseSIMState(m.execTelemetry(ctx, at.CmdSimStatus))
+	if err != nil {
+		return SIMUnknown, fmt.Errorf("query SIM status: %w", err)
+	}
+	if last := SIMState(m.simState.Swap(int32(state))); last != state {
+		m.recordLifecycle(LifecycleSIMChanged, fmt.Sprintf("%s -> %s", last, state))
+	}
+	return state, nil
+}
+
+// simErrors maps the +CME ERROR codes of 3GPP TS 27.007, numeric and
+// verbose, that describe the SIM state.
+var simErrors = map[string]SIMState{
+	"10":               SIMNotInserted,
+	"SIM not inserted": SIMNotInserted,
+	"11":               SIMPINRequired,
+	"SIM PIN required": SIMPINRequired,
+	"12":               SIMPUKRequired,
+	"SIM PUK required": SIMPUKRequired,
+	"14":               SIMBusy,
+	"SIM busy":         SIMBusy,
+}
+
+// parseSIMState parses the response to AT+CPIN? and the error it was
+// answered with.
+func parseSIMState(resp string, err error) (SIMState, error) {
+	if err != nil {
+		if reason, ok := strings.CutPrefix(err.Error(), at.CmeError); ok {
+			if state, ok := simErrors[strings.TrimSpace(reason)]; ok {
+				return state, nil
+			}
+		}
+		return SIMUnknown, err
+	}
+
+	code, ok := at.Params(resp, at.SimStatus)
+	if !ok {
+		return SIMUnknown, fmt.Errorf("unexpected SIM status response: %q", resp)
+	}
+	switch code {
+	case "READY":
+		return SIMReady, nil
+	case "SIM PIN":
+		return SIMPINRequired, nil
+	case "SIM PUK":
+		return SIMPUKRequired, nil
+	default:
+		return SIMUnknown, nil
+	}
+}
+
+// pinCounters are the vendor commands reporting the remaining PIN attempts,
+// tried in order. field is the index of the PIN attempts in the response.
+var pinCounters = []struct {
+	cmd    string
+	prefix string
+	field  int
+}{
+	// Quectel: +QPINC: "SC",<pin_times>,<puk_times>
+	{cmd: at.CmdPinCounter, prefix: at.PinCounter, field: 1},
+	// Huawei: ^CPIN: <code>,<times>,<puk_times>,<pin_times>,...
+	{cmd: at.CmdPinStatus, prefix: at.PinStatus, field: 3},
+}
+
+// pinAttemptsDirect returns the number of PIN attempts left. ok is false if
+// the modem supports none of the known counter commands.
+func (m *Modem) pinAttemptsDirect(ctx context.Context) (attempts int, ok bool, err error) {
+	for _, counter := range pinCounters {
+		resp, err := m.execDirect(ctx, counter.cmd)
+		if err != nil {
+			if isFatalDirectErr(err) || ctx.Err() != nil {
+				return 0, false, err
+			}
+			// Not supported by this modem
+			continue
+		}
+		params, found := at.Params(resp, counter.prefix)
+		if !found {
+			continue
+		}
+		fields := at.SplitFields(params)
+		if counter.field >= len(fields) {
+			continue
+		}
+		if n, err := strconv.Atoi(fields[counter.field]); err == nil {
+			return n, true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,142 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestSIMStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected modem.SIMState
+		wantErr  bool
+	}{
+		{name: "Ready", response: "+CPIN: READY\r\nOK\r\n", expected: modem.SIMReady},
+		{name: "PIN required", response: "+CPIN: SIM PIN\r\nOK\r\n", expected: modem.SIMPINRequired},
+		{name: "PUK required", response: "+CPIN: SIM PUK\r\nOK\r\n", expected: modem.SIMPUKRequired},
+		{name: "Other lock", response: "+CPIN: PH-SIM PIN\r\nOK\r\n", expected: modem.SIMUnknown},
+		{name: "Not inserted, verbose", response: "+CME ERROR: SIM not inserted\r\n", expected: modem.SIMNotInserted},
+		{name: "Not inserted, numeric", response: "+CME ERROR: 10\r\n", expected: modem.SIMNotInserted},
+		{name: "Busy", response: "+CME ERROR: SIM busy\r\n", expected: modem.SIMBusy},
+		{name: "Other error", response: "+CME ERROR: SIM failure\r\n", expected: modem.SIMUnknown, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTransport := modem.NewMockTransport(ctrl)
+			mockDialer := modem.NewMockDialer(ctrl)
+
+			gomock.InOrder(
+				slices.Concat(
+					[]any{
+						mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+					},
+					initMockCalls(mockTransport),
+				)...,
+			)
+
+			config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+			if err != nil {
+				t.Fatalf("unexpected error from Build(): %v", err)
+			}
+
+			ctx := context.Background()
+			m, err := modem.New(ctx, config)
+			if err != nil {
+				t.Fatalf("failed to create modem: %v", err)
+			}
+			defer m.Close()
+
+			eof := expectExchanges(mockTransport, Exchange{"AT+CPIN?\r", tt.response})
+			mockTransport.EXPECT().Close().Return(nil)
+
+			go m.Loop(ctx)
+
+			state, err := m.SIMStatus(ctx)
+			eof()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if state != tt.expected {
+				t.Errorf("expected %s, got: %s", tt.expected, state)
+			}
+		})
+	}
+}
+
+func TestModemNewSIMState(t *testing.T) {
+	t.Run("Fails without SIM card", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			NewMockSequence(mockTransport).
+				AT().
+				EchoOff().
+				VerboseErrors().
+				Build(),
+			[]any{
+				mockTransport.EXPECT().Write([]byte("AT+CPIN?\r")).Return(9, nil),
+				mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+					return copy(p, "+CME ERROR: SIM not inserted\r\n"), nil
+				}),
+				mockTransport.EXPECT().Close(),
+			},
+		)...)
+
+		config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		_, err = modem.New(context.Background(), config)
+		if err == nil || !strings.Contains(err.Error(), "not inserted") {
+			t.Fatalf("expected unsupported SIM state error, got: %v", err)
+		}
+	})
+}
+
+// BenchmarkSIMStatus measures a command round trip through the Loop.
+func BenchmarkSIMStatus(b *testing.B) {
+	transport := newScriptedTransport(map[string]string{
+		"AT+CPIN?": "+CPIN: READY\r\nOK\r\n",
+		"AT+CSCS?": "+CSCS: \"GSM\"\r\nOK\r\n",
+		"AT+CREG?": "+CREG: 0,1\r\nOK\r\n",
+	})
+	config, err := modem.NewConfigBuilder().
+		WithDialer(scriptedDialer{transport}).
+		Build()
+	if err != nil {
+		b.Fatalf("unexpected error from Build(): %v", err)
+	}
+
+	ctx := context.Background()
+	m, err := modem.New(ctx, config)
+	if err != nil {
+		b.Fatalf("failed to create modem: %v", err)
+	}
+	defer m.Close()
+	go m.Loop(ctx)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := m.SIMStatus(ctx); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
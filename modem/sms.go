@@ -2,10 +2,15 @@ package modem
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode/utf16"
 
 	"i4.energy/across/smsgw/at"
+	"i4.energy/across/smsgw/pdu"
 )
 
 // SMS represents a text message stored on the modem.
@@ -13,20 +18,139 @@ type SMS struct {
 	Index  int
 	Status string // "REC UNREAD", "REC READ", "STO UNSENT", "STO SENT"
 	Sender string
-	Time   string
-	Text   string
+	// Time is the service center time stamp, in the time zone it was
+	// given in
+	Time time.Time
+	Text string
+}
+
+// NewMessageMode selects how the modem reports incoming messages (AT+CNMI).
+type NewMessageMode int
+
+const (
+	// NewMessageUnchanged leaves the modem's indication setting untouched
+	NewMessageUnchanged NewMessageMode = iota
+	// NewMessageStore stores incoming messages and reports their storage
+	// index with a +CMTI URC
+	NewMessageStore
+	// NewMessageDirect delivers incoming messages with a +CMT URC carrying
+	// the complete message, without storing it on the modem
+	NewMessageDirect
+)
+
+// command returns the AT+CNMI command selecting the mode, or an empty
+// string if the setting should be left untouched.
+func (n NewMessageMode) command() string {
+	switch n {
+	case NewMessageStore:
+		return at.CmdNewMsgStore
+	case NewMessageDirect:
+		return at.CmdNewMsgDirect
+	default:
+		return ""
+	}
+}
+
+// ParseCMT parses a +CMT URC, as delivered on the URC channel in direct
+// delivery mode with header and body separated by a newline, into an SMS.
+// Directly delivered messages are not stored, so Index and Status are empty.
+func ParseCMT(urc string) (SMS, error) {
+	header, body, ok := strings.Cut(urc, "\n")
+	rest, isCMT := strings.CutPrefix(header, at.UrcNewMsgDirect)
+	if !ok || !isCMT {
+		return SMS{}, fmt.Errorf("not a +CMT URC: %q", urc)
+	}
+
+	// +CMT: <oa>,[<alpha>],<scts>[,...]
+	fields := at.SplitFields(rest)
+	if len(fields) < 3 {
+		return SMS{}, fmt.Errorf("malformed +CMT header: %q", header)
+	}
+
+	scts, err := at.ParseTimestamp(fields[2])
+	if err != nil {
+		return SMS{}, err
+	}
+
+	return SMS{
+		Sender: fields[0],
+		Time:   scts,
+		Text:   body,
+	}, nil
+}
+
+// BinaryMessage is an 8-bit data SMS, such as a WAP push or a device
+// management trigger for a remote IoT device.
+type BinaryMessage struct {
+	// DestinationPort and SourcePort add 16-bit application port addressing
+	// to the user data header when either of them is non-zero.
+	DestinationPort uint16
+	SourcePort      uint16
+	// UDH holds additional raw user data header information elements,
+	// without the leading length octet.
+	UDH []byte
+	// Data is the binary payload.
+	Data []byte
 }
 
 // SendSMS sends a text message to the specified recipient.
 //
 // The message is sent in text mode (not PDU mode). The recipient should be
-// in international format (e.g., "+1234567890").
+// in international format (e.g., "+1234567890"). Recipient and message are
+// encoded in the character set selected during initialization. Whatever the
+// character set, the modem sends text mode messages in the GSM 7-bit
+// alphabet, so text mode only passes characters coded alike in ASCII and
+// that alphabet unchanged. Other messages are sent in PDU mode instead, so
+// that they are not mangled: in GSM 7-bit if they fit the alphabet, such as
+// "café £5", and as UCS-2 otherwise, such as Greek, Cyrillic or emoji, split
+// into 67 characters per part beyond 70, see SendSMSEncoded.
 //
-// This method blocks until the message is accepted by the network or an error
+// The recipient must consist of dialling digits (0-9, *, #) with an optional
+// leading "+", otherwise ErrInvalidRecipient is returned. Line breaks in
+// the message are sent as LF, other control characters are rejected with
+// ErrInvalidMessage as they would end or abort the text entry.
+//
+// With a sandbox recipient configured, the message is sent to it instead,
+// see WithSandboxRecipient.
+//
+// Sends are spaced at least the configured minimum send interval apart, see
+// NextSendIn, and are held while the modem is paused, see Pause. This
+// method blocks until the message is accepted by the network or an error
 // occurs. Network delivery (to the final recipient) happens asynchronously.
 func (m *Modem) SendSMS(ctx context.Context, recipient, message string) error {
+	recipient, message, err := m.prepareText(recipient, message)
+	if err != nil {
+		return err
+	}
+	if !pdu.ASCIICompatible(message) {
+		return m.sendEncoded(ctx, recipient, message, textEncoding(message))
+	}
+	return m.submit(ctx, func() error {
+		return m.sendText(ctx, recipient, message)
+	})
+}
+
+// prepareText validates a text message and applies the sandbox recipient.
+// It returns the recipient and message to submit.
+func (m *Modem) prepareText(recipient, message string) (string, string, error) {
+	if err := validateRecipient(recipient); err != nil {
+		return "", "", err
+	}
+	if sandbox := m.config.sandboxRecipient; sandbox != "" {
+		message += "\n[to: " + recipient + "]"
+		recipient = sandbox
+	}
+	message, err := m.sanitizeMessage(message)
+	if err != nil {
+		return "", "", err
+	}
+	return recipient, message, nil
+}
+
+// sendText submits a validated text message with AT+CMGS.
+func (m *Modem) sendText(ctx context.Context, recipient, message string) error {
 	// Use exec to send the initial command and get the prompt
-	resp, err := m.exec(ctx, fmt.Sprintf(`AT+CMGS="%s"`, recipient))
+	resp, err := m.exec(ctx, at.CMGS(m.encodeText(recipient)))
 	if err != nil {
 		return fmt.Errorf("AT+CMGS command failed: %w", err)
 	}
@@ -38,8 +162,7 @@ func (m *Modem) SendSMS(ctx context.Context, recipient, message string) error {
 
 	// Now send the message body and wait for confirmation
 	// This is essentially another exec(), but we just send the message text
-	messageCmd := message + at.CtrlZ
-	resp, err = m.exec(ctx, messageCmd)
+	resp, err = m.submitBody(ctx, m.encodeText(message))
 	if err != nil {
 		return fmt.Errorf("SMS send failed: %w", err)
 	}
@@ -51,3 +174,286 @@ func (m *Modem) SendSMS(ctx context.Context, recipient, message string) error {
 
 	return nil
 }
+
+// validateRecipient checks that a text mode recipient only contains
+// characters that are safe inside the quoted AT+CMGS parameter.
+func validateRecipient(recipient string) error {
+	digits := strings.TrimPrefix(recipient, "+")
+	if digits == "" {
+		return fmt.Errorf("%w: empty number", ErrInvalidRecipient)
+	}
+	for _, c := range digits {
+		if (c < '0' || c > '9') && c != '*' && c != '#' {
+			return fmt.Errorf("%w: unexpected character %q in %q", ErrInvalidRecipient, c, recipient)
+		}
+	}
+	return nil
+}
+
+// sanitizeMessage prepares a message for text entry. A CR would make the
+// modem start a new prompt, so line breaks are normalized to LF. Ctrl-Z and
+// ESC end the text entry and other control characters are not part of the
+// GSM alphabet, they are rejected. UCS2 text is hex encoded and passes as is.
+func (m *Modem) sanitizeMessage(message string) (string, error) {
+	if m.config.charset == at.CharsetUCS2 {
+		return message, nil
+	}
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	message = strings.ReplaceAll(message, "\r", "\n")
+	for _, c := range message {
+		if c < 0x20 && c != '\n' || c == 0x7F {
+			return "", fmt.Errorf("%w: control character %q", ErrInvalidMessage, c)
+		}
+	}
+	return message, nil
+}
+
+// encodeText converts a text mode parameter to the TE character set selected
+// during initialization. GSM and IRA are passed through as is, which is only
+// safe for text that pdu.ASCIICompatible accepts. UCS2 expects the UTF-16
+// code units as hexadecimal digits.
+func (m *Modem) encodeText(s string) string {
+	if m.config.charset != at.CharsetUCS2 {
+		return s
+	}
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// SendBinarySMS sends an 8-bit binary message to the specified recipient.
+//
+// Binary messages cannot be expressed in text mode, so the modem is switched
+// to PDU mode for the duration of the send and put back into text mode
+// afterwards, even if sending fails. Like SendSMS, it honors the minimum
+// send interval and the sandbox recipient and is held while the modem is
+// paused.
+func (m *Modem) SendBinarySMS(ctx context.Context, recipient string, msg BinaryMessage) error {
+	// The recipient is validated even if the sandbox replaces it, so that
+	// a message failing in production fails in the sandbox as well
+	if err := validateRecipient(recipient); err != nil {
+		return err
+	}
+
+	udh := msg.UDH
+	if msg.DestinationPort != 0 || msg.SourcePort != 0 {
+		udh = append(pdu.PortAddressing16(msg.DestinationPort, msg.SourcePort), udh...)
+	}
+
+	if sandbox := m.config.sandboxRecipient; sandbox != "" {
+		recipient = sandbox
+	}
+
+	tpdu, err := pdu.Submit{
+		Recipient: recipient,
+		DCS:       pdu.DCS8Bit,
+		UDH:       udh,
+		UserData:  msg.Data,
+	}.Encode()
+	if err != nil {
+		return fmt.Errorf("encode PDU: %w", err)
+	}
+	return m.submit(ctx, func() error {
+		return m.sendPDU(ctx, [][]byte{tpdu})
+	})
+}
+
+// submit runs send as the next message submission. The caller is held
+// while sending is paused, until the minimum send interval passed and
+// until the submission in progress, if any, is done: the text entry of
+// one message must not be interleaved with the commands of another.
+func (m *Modem) submit(ctx context.Context, send func() error) error {
+	m.queued.Add(1)
+	defer m.queued.Add(-1)
+
+	release, err := m.admit(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	m.keepLinkOpen(ctx)
+	return m.countSend(send())
+}
+
+// admit waits until a message may be submitted: sending is not paused, its
+// send interval slot is due and no other message is being submitted. A
+// message that was paused while it waited for its slot or the submission
+// in progress starts over, so that Pause also holds the messages already
+// queued. The returned function must be called when done.
+func (m *Modem) admit(ctx context.Context) (release func(), err error) {
+	for {
+		if err := m.sending.wait(ctx); err != nil {
+			return nil, fmt.Errorf("wait for resume: %w", err)
+		}
+		if err := m.pacer.wait(ctx); err != nil {
+			return nil, fmt.Errorf("wait for send interval: %w", err)
+		}
+		release, err := m.acquireSubmission(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !m.sending.isClosed() {
+			return release, nil
+		}
+		release()
+		m.config.logger.Info("queued message held by pause")
+	}
+}
+
+// acquireSubmission waits until no other message is being submitted or
+// stored. The returned function must be called when done.
+func (m *Modem) acquireSubmission(ctx context.Context) (release func(), err error) {
+	select {
+	case m.submitting <- struct{}{}:
+		return func() { <-m.submitting }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("wait for submission in progress: %w", ctx.Err())
+	}
+}
+
+// keepLinkOpen asks the modem to keep the radio link to the network open
+// after the next message if more messages are queued behind it, which
+// saves setting up the link for each of them (AT+CMMS=1). The modem closes
+// the link on its own when no message follows within a few seconds. If
+// the modem rejects the command, it is not sent again.
+func (m *Modem) keepLinkOpen(ctx context.Context) {
+	if !m.config.moreMessages || m.queued.Load() < 2 || m.moreMessagesUnsupported.Load() {
+		return
+	}
+	_, err := m.exec(ctx, at.CmdMoreMessages)
+	switch {
+	case err == nil:
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrAlreadyClosed):
+		// Not an answer of the modem, the send fails the same way
+	default:
+		m.moreMessagesUnsupported.Store(true)
+		m.config.logger.Warn("modem does not support AT+CMMS, sending without keeping the link open", "err", err)
+	}
+}
+
+// QueuedMessages returns the number of messages submitted with SendSMS or
+// SendBinarySMS that are not sent yet, including the one being sent.
+func (m *Modem) QueuedMessages() int {
+	return int(m.queued.Load())
+}
+
+// countSend reports the outcome of a submitted message to the metrics and
+// returns err unchanged.
+func (m *Modem) countSend(err error) error {
+	if err != nil {
+		m.config.metrics.AddCounter(MetricSMSFailed, 1)
+	} else {
+		m.config.metrics.AddCounter(MetricSMSSent, 1)
+	}
+	return err
+}
+
+// sendPDU submits encoded TPDUs, such as the parts of a concatenated
+// message, with AT+CMGS in PDU mode and restores text mode when done.
+func (m *Modem) sendPDU(ctx context.Context, tpdus [][]byte) error {
+	return m.inPDUMode(ctx, func() error {
+		return m.submitPDUs(ctx, tpdus)
+	})
+}
+
+// inPDUMode runs fn with the modem switched to PDU mode and puts it back
+// into text mode afterwards, even if fn fails.
+func (m *Modem) inPDUMode(ctx context.Context, fn func() error) (err error) {
+	if _, err := m.exec(ctx, at.CmdSetPDUMode); err != nil {
+		return fmt.Errorf("select PDU mode: %w", err)
+	}
+	defer func() {
+		// Restore text mode even if the caller's context is already done,
+		// otherwise every later text mode command would fail.
+		restoreCtx, cancel := m.cleanupContext(ctx)
+		defer cancel()
+		if _, rerr := m.exec(restoreCtx, at.CmdSetTextMode); rerr != nil && err == nil {
+			err = fmt.Errorf("restore SMS text mode: %w", rerr)
+		}
+	}()
+	return fn()
+}
+
+// submitPDUs sends TPDUs with AT+CMGS, the modem must be in PDU mode.
+func (m *Modem) submitPDUs(ctx context.Context, tpdus [][]byte) error {
+	for _, tpdu := range tpdus {
+		resp, err := m.exec(ctx, at.CMGSPDU(len(tpdu)))
+		if err != nil {
+			return fmt.Errorf("AT+CMGS command failed: %w", err)
+		}
+		if !strings.Contains(resp, at.Prompt) {
+			return fmt.Errorf("did not receive SMS prompt, got: %q", resp)
+		}
+
+		// A zero-length SMSC field makes the modem use the SIM's default SMSC
+		body := "00" + strings.ToUpper(hex.EncodeToString(tpdu))
+		resp, err = m.submitBody(ctx, body)
+		if err != nil {
+			return fmt.Errorf("SMS send failed: %w", err)
+		}
+		if !strings.Contains(resp, at.OK) {
+			return fmt.Errorf("unexpected SMS response: %s", resp)
+		}
+	}
+
+	return nil
+}
+
+// submitBody ends the text entry of an AT+CMGS command with the message
+// body and returns the response. It leaves the text entry if the body could
+// not be written. The time until the network accepted the message is
+// recorded as MetricSubmitDuration, a rising latency is an early sign of
+// degrading carrier conditions.
+func (m *Modem) submitBody(ctx context.Context, body string) (string, error) {
+	start := m.config.clock.Now()
+	resp, err := m.exec(ctx, body+at.CtrlZ)
+	if err != nil {
+		m.leavePrompt(ctx)
+		return "", err
+	}
+	if strings.Contains(resp, at.OK) {
+		m.observeAcceptance(start)
+	}
+	return resp, nil
+}
+
+// observeAcceptance records the time since start, when a message was
+// handed to the modem, as network acceptance latency.
+func (m *Modem) observeAcceptance(start time.Time) {
+	latency := m.config.clock.Now().Sub(start)
+	m.config.metrics.ObserveHistogram(MetricSubmitDuration, latency.Seconds())
+	m.config.logger.Debug("message accepted by network", "latency", latency)
+}
+
+// NextSendIn returns how long a message submitted now would wait for the
+// minimum send interval, including the messages already waiting. It is zero
+// when the next message can be sent right away.
+func (m *Modem) NextSendIn() time.Duration {
+	return m.pacer.remaining()
+}
+
+// leavePrompt makes sure the modem does not remain in SMS text entry after
+// the message body could not be submitted, for example because writing it
+// failed or the caller gave up first. The modem would otherwise take every
+// later command as message text.
+func (m *Modem) leavePrompt(ctx context.Context) {
+	abortCtx, cancel := m.cleanupContext(ctx)
+	defer cancel()
+	if err := m.Abort(abortCtx); err != nil {
+		m.config.logger.Warn("leave SMS text entry", "err", err)
+	}
+}
+
+// cleanupContext returns a context for cleanup commands that must run even
+// if ctx is already done. It keeps the values of ctx and is bounded by the
+// AT timeout instead.
+func (m *Modem) cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+	if m.config.atTimeout > 0 {
+		return context.WithTimeout(ctx, m.config.atTimeout)
+	}
+	return ctx, func() {}
+}
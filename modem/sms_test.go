@@ -4,11 +4,14 @@ import (
 	context "context"
 	"errors"
 	"io"
+	"log/slog"
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/at"
 	"i4.energy/across/smsgw/modem"
 )
 
@@ -229,6 +232,576 @@ func TestSendSMS(t *testing.T) {
 		}
 	})
 
+	t.Run("Encodes recipient and text in UCS2 character set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("UCS2").
+					Registration("1").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithCharset(at.CharsetUCS2).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{`AT+CMGS="002B00330030"` + "\r", "> "},
+			Exchange{"00480069\x1a\r", "+CMGS: 7\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		err = m.SendSMS(ctx, "+30", "Hi")
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Sends long non-GSM text as UCS-2 PDUs with the UCS2 character set", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				NewMockSequence(mockTransport).
+					AT().
+					EchoOff().
+					VerboseErrors().
+					SimReady().
+					SMSTextMode().
+					Charset("UCS2").
+					Registration("1").
+					Build(),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithCharset(at.CharsetUCS2).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// Text mode would send the message in the GSM 7-bit alphabet and
+		// unsplit, 67 characters fit next to the concatenation header
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=152\r", "> "},
+			Exchange{"0041000A91214365870900088C050003010201" + strings.Repeat("0416", 67) + "\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGS=26\r", "> "},
+			Exchange{"0041000A91214365870900080E050003010202" + strings.Repeat("0416", 4) + "\x1a\r", "+CMGS: 6\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		err = m.SendSMS(ctx, "+1234567890", strings.Repeat("Ж", 71))
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Waits for the minimum send interval", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		clock := newFakeClock()
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithClock(clock).
+			WithMinSendInterval(time.Minute).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"first\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"second\x1a\r", "+CMGS: 2\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		if err := m.SendSMS(ctx, "+1234567890", "first"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := m.NextSendIn(); got != time.Minute {
+			t.Errorf("expected next send in 1m, got: %s", got)
+		}
+
+		sent := make(chan error, 1)
+		go func() {
+			sent <- m.SendSMS(ctx, "+1234567890", "second")
+		}()
+
+		// The second send waits for its slot
+		clock.WaitForWaiter()
+		clock.Advance(30 * time.Second)
+		select {
+		case err := <-sent:
+			t.Fatalf("expected send to wait, got: %v", err)
+		default:
+		}
+		if got := m.NextSendIn(); got != 90*time.Second {
+			t.Errorf("expected next send in 1m30s, got: %s", got)
+		}
+
+		clock.Advance(30 * time.Second)
+		if err := <-sent; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		eof()
+	})
+
+	t.Run("Redirects to the sandbox recipient", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithSandboxRecipient("+15550100").
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The brackets of the tag are not coded alike in ASCII and GSM 7-bit
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=33\r", "> "},
+			Exchange{"000100089151551000000019C8329BFD566C78F4B70EB48AC966B49AED86CBC1363E\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		err = m.SendSMS(ctx, "+1234567890", "Hello")
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Holds sends while paused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// Only the message sent after Resume reaches the modem
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"held\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		m.Pause()
+		if !m.Paused() {
+			t.Fatal("expected modem to be paused")
+		}
+
+		cancelled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if err := m.SendSMS(cancelled, "+1234567890", "dropped"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+		}
+
+		sent := make(chan error, 1)
+		go func() {
+			sent <- m.SendSMS(ctx, "+1234567890", "held")
+		}()
+		select {
+		case err := <-sent:
+			t.Fatalf("expected send to be held, got: %v", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		m.Resume()
+		if m.Paused() {
+			t.Error("expected modem to be resumed")
+		}
+		if err := <-sent; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		eof()
+	})
+
+	t.Run("Holds sends waiting for their interval when paused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		clock := newFakeClock()
+		logs := newLogLines()
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithClock(clock).
+			WithMinSendInterval(time.Minute).
+			WithLogger(slog.New(slog.NewTextHandler(logs, nil))).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// The queued messages only reach the modem after Resume
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"first\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"queued\x1a\r", "+CMGS: 2\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"queued\x1a\r", "+CMGS: 3\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		if err := m.SendSMS(ctx, "+1234567890", "first"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		sent := make(chan error, 2)
+		for range 2 {
+			go func() {
+				sent <- m.SendSMS(ctx, "+1234567890", "queued")
+			}()
+		}
+		clock.WaitForWaiter()
+		clock.WaitForWaiter()
+
+		// Both slots come due while paused
+		m.Pause()
+		clock.Advance(2 * time.Minute)
+		logs.waitFor(t, "queued message held by pause")
+		logs.waitFor(t, "queued message held by pause")
+
+		// Resumed, they wait for new slots
+		m.Resume()
+		clock.WaitForWaiter()
+		clock.WaitForWaiter()
+		clock.Advance(2 * time.Minute)
+		for range 2 {
+			if err := <-sent; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		eof()
+	})
+
+	t.Run("Keeps the link open while messages are queued", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			WithMoreMessagesToSend(true).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// Only the first message has another one queued behind it
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMMS=1\r", "OK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		// Queue the messages while paused, so they are all waiting
+		m.Pause()
+		sent := make(chan error, 2)
+		for range 2 {
+			queue := newQueueSignal(t)
+			go func() {
+				sent <- m.SendSMS(queue, "+1234567890", "Hello")
+			}()
+			<-queue.queued
+		}
+		m.Resume()
+
+		for range 2 {
+			if err := <-sent; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		eof()
+		if got := m.QueuedMessages(); got != 0 {
+			t.Errorf("expected no queued messages, got %d", got)
+		}
+	})
+
+	t.Run("Sends without AT+CMMS if the modem rejects it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			WithMoreMessagesToSend(true).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		// AT+CMMS is not tried again after the modem rejected it
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMMS=1\r", "ERROR\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"Hello\x1a\r", "+CMGS: 1\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		// Queue the messages while paused, so they are all waiting
+		m.Pause()
+		sent := make(chan error, 3)
+		for range 3 {
+			queue := newQueueSignal(t)
+			go func() {
+				sent <- m.SendSMS(queue, "+1234567890", "Hello")
+			}()
+			<-queue.queued
+		}
+		m.Resume()
+
+		for range 3 {
+			if err := <-sent; err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		eof()
+		if got := m.QueuedMessages(); got != 0 {
+			t.Errorf("expected no queued messages, got %d", got)
+		}
+	})
+
+	t.Run("Leaves text entry when the body cannot be written", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		ctx := context.Background()
+		m, err := modem.New(ctx, config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		defer m.Close()
+
+		writeErr := errors.New("write failed")
+		mockTransport.EXPECT().Write([]byte("Hello\x1a\r")).Return(0, writeErr)
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"\x1b", ""},
+			Exchange{"AT\r", "\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(ctx)
+
+		err = m.SendSMS(ctx, "+1234567890", "Hello")
+		eof()
+		if !errors.Is(err, writeErr) {
+			t.Errorf("expected write error to be wrapped, got: %v", err)
+		}
+	})
+
 	t.Run("Error on closed modem", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -265,3 +838,254 @@ func TestSendSMS(t *testing.T) {
 		}
 	})
 }
+
+func TestSendSMSInjection(t *testing.T) {
+	newModem := func(t *testing.T, ctrl *gomock.Controller) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	tests := []struct {
+		name      string
+		recipient string
+		message   string
+		expected  error
+	}{
+		{"Quote in recipient", "+123\",1,2\r\nAT+CFUN=0", "Hello", modem.ErrInvalidRecipient},
+		{"CR in recipient", "+123\rAT+CFUN=0", "Hello", modem.ErrInvalidRecipient},
+		{"Letters in recipient", "ACME", "Hello", modem.ErrInvalidRecipient},
+		{"Empty recipient", "+", "Hello", modem.ErrInvalidRecipient},
+		{"Ctrl-Z in message", "+1234567890", "Hi\x1aAT+CFUN=0\r", modem.ErrInvalidMessage},
+		{"ESC in message", "+1234567890", "Hi\x1b", modem.ErrInvalidMessage},
+		{"NUL in message", "+1234567890", "Hi\x00", modem.ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Rejected before anything is written to the modem
+			m, mockTransport := newModem(t, ctrl)
+			mockTransport.EXPECT().Close().Return(nil)
+			defer m.Close()
+
+			err := m.SendSMS(context.Background(), tt.recipient, tt.message)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got: %v", tt.expected, err)
+			}
+		})
+	}
+
+	t.Run("Line breaks are sent as LF", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGS=\"+1234567890\"\r", "> "},
+			Exchange{"one\ntwo\nthree\x1a\r", "+CMGS: 7\r\nOK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendSMS(context.Background(), "+1234567890", "one\r\ntwo\rthree")
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSendBinarySMS(t *testing.T) {
+	// newLoopingModem initializes a modem and starts its Loop
+	newLoopingModem := func(t *testing.T, ctrl *gomock.Controller, builder *modem.ConfigBuilder) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := builder.WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	wapPush := modem.BinaryMessage{
+		DestinationPort: 2948,
+		SourcePort:      9200,
+		Data:            []byte{0x01, 0x06},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder())
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=21\r", "> "},
+			Exchange{"0041000A9121436587090004090605040B8423F00106\x1a\r", "+CMGS: 5\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendBinarySMS(context.Background(), "+1234567890", wapPush)
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Restores text mode on network rejection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder())
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGS=21\r", "> "},
+			Exchange{"0041000A9121436587090004090605040B8423F00106\x1a\r", "+CMS ERROR: 500\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendBinarySMS(context.Background(), "+1234567890", wapPush)
+		eof()
+		if err == nil || !strings.Contains(err.Error(), "+CMS ERROR: 500") {
+			t.Errorf("expected network error to be wrapped, got: %v", err)
+		}
+	})
+
+	t.Run("Rejects invalid recipient before touching the modem", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder())
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		err := m.SendBinarySMS(context.Background(), "not-a-number", wapPush)
+		if !errors.Is(err, modem.ErrInvalidRecipient) {
+			t.Errorf("expected ErrInvalidRecipient, got: %v", err)
+		}
+	})
+
+	t.Run("Rejects invalid recipient in the sandbox", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder().
+			WithSandboxRecipient("+15550100"))
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		err := m.SendBinarySMS(context.Background(), "not-a-number", wapPush)
+		if !errors.Is(err, modem.ErrInvalidRecipient) {
+			t.Errorf("expected ErrInvalidRecipient, got: %v", err)
+		}
+	})
+}
+
+func TestParseCMT(t *testing.T) {
+	t.Run("Header and body", func(t *testing.T) {
+		sms, err := modem.ParseCMT("+CMT: \"+1234567890\",\"\",\"24/01/15,10:30:00+08\"\nPump 4, level low")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := modem.SMS{
+			Sender: "+1234567890",
+			Time:   time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("", 2*60*60)),
+			Text:   "Pump 4, level low",
+		}
+		if sms.Sender != expected.Sender || !sms.Time.Equal(expected.Time) || sms.Text != expected.Text {
+			t.Errorf("expected %+v, got %+v", expected, sms)
+		}
+		if _, offset := sms.Time.Zone(); offset != 2*60*60 {
+			t.Errorf("expected time zone +02:00, got offset %ds", offset)
+		}
+	})
+
+	t.Run("Time stamp time zones", func(t *testing.T) {
+		tests := []struct {
+			scts     string
+			expected time.Time
+		}{
+			{"24/01/15,10:30:00-20", time.Date(2024, 1, 15, 15, 30, 0, 0, time.UTC)},
+			{"24/01/15,10:30:00+00", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+			{"24/01/15,10:30:00", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		}
+		for _, tt := range tests {
+			sms, err := modem.ParseCMT("+CMT: \"+1234567890\",,\"" + tt.scts + "\"\nbody")
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.scts, err)
+				continue
+			}
+			if !sms.Time.Equal(tt.expected) {
+				t.Errorf("%s: expected %s, got %s", tt.scts, tt.expected, sms.Time)
+			}
+		}
+	})
+
+	t.Run("Error on malformed time stamp", func(t *testing.T) {
+		if _, err := modem.ParseCMT("+CMT: \"+1234567890\",,\"15/01/2024 10:30\"\nbody"); err == nil {
+			t.Error("expected error for malformed time stamp")
+		}
+	})
+
+	t.Run("Error on other URC", func(t *testing.T) {
+		if _, err := modem.ParseCMT("+CMTI: \"SM\",1"); err == nil {
+			t.Error("expected error for +CMTI URC")
+		}
+	})
+
+	t.Run("Error on truncated header", func(t *testing.T) {
+		if _, err := modem.ParseCMT("+CMT: \"+1234567890\"\nbody"); err == nil {
+			t.Error("expected error for truncated header")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package modem
+
+import (
+	"strings"
+	"time"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// State is a snapshot of the internal state of a Modem, for diagnosing a
+// modem that hangs or falls behind on a remote device.
+type State struct {
+	// LoopRunning reports whether Loop is running
+	LoopRunning bool `json:"loop_running"`
+	// Ready reports whether the modem registered to the network
+	Ready bool `json:"ready"`
+	// Paused reports whether sending is paused
+	Paused bool `json:"paused"`
+	// Closed reports whether Close was called
+	Closed bool `json:"closed"`
+	// Pending is the command the Loop waits for an answer to, nil if none
+	Pending *PendingCommand `json:"pending,omitempty"`
+	// QueuedMessages is the number of messages waiting to be sent,
+	// including the one being sent
+	QueuedMessages int `json:"queued_messages"`
+	// URCBacklog is the number of URCs nobody received yet, out of
+	// URCCapacity before they are dropped
+	URCBacklog  int `json:"urc_backlog"`
+	URCCapacity int `json:"urc_capacity"`
+	// LastError is the error that ended the last Loop run
+	LastError string `json:"last_error,omitempty"`
+	// Events counts the loop events since the modem was created
+	Events map[LoopEvent]uint64 `json:"events,omitempty"`
+}
+
+// PendingCommand is the command in flight.
+type PendingCommand struct {
+	// Command is the AT command without its parameters, which may hold
+	// a PIN or phone number, or "message text" for the text of a message
+	Command string `json:"command"`
+	// Since is when the command was written to the modem
+	Since time.Time `json:"since"`
+}
+
+// State returns a snapshot of the internal state of the modem. It is safe
+// to call at any time, also while the Loop is stuck.
+func (m *Modem) State() State {
+	s := State{
+		LoopRunning:    m.loopRunning.Load(),
+		Ready:          m.Ready(),
+		Paused:         m.Paused(),
+		Closed:         m.closed.Load(),
+		Pending:        m.inFlight.Load(),
+		QueuedMessages: m.QueuedMessages(),
+		URCBacklog:     len(m.urcChan),
+		URCCapacity:    cap(m.urcChan),
+		Events:         m.Diagnostics(),
+	}
+	if err := m.Err(); err != nil {
+		s.LastError = err.Error()
+	}
+	return s
+}
+
+// commandName returns cmd without its parameters, so that State does not
+// expose PINs, phone numbers or message text.
+func commandName(cmd string) string {
+	if strings.HasSuffix(cmd, at.CtrlZ) {
+		return "message text"
+	}
+	cmd = strings.TrimSpace(cmd)
+	if i := strings.IndexByte(cmd, '='); i >= 0 {
+		return cmd[:i+1]
+	}
+	return cmd
+}
@@ -0,0 +1,86 @@
+package modem_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransport := modem.NewMockTransport(ctrl)
+	mockDialer := modem.NewMockDialer(ctrl)
+
+	gomock.InOrder(
+		slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+		)...,
+	)
+
+	config, err := modem.NewConfigBuilder().WithDialer(mockDialer).Build()
+	if err != nil {
+		t.Fatalf("unexpected error from Build(): %v", err)
+	}
+	ctx := context.Background()
+	m, err := modem.New(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to create modem: %v", err)
+	}
+	defer m.Close()
+
+	// The modem takes its time to delete the message
+	answer := make(chan struct{})
+	written := make(chan struct{})
+	mockTransport.EXPECT().Write([]byte("AT+CMGD=3\r")).DoAndReturn(func(p []byte) (int, error) {
+		close(written)
+		return len(p), nil
+	})
+	mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		<-answer
+		return copy(p, "OK\r\n"), nil
+	})
+	eof := expectExchanges(mockTransport)
+	mockTransport.EXPECT().Close().Return(nil)
+
+	if state := m.State(); state.LoopRunning || !state.Ready || state.Pending != nil {
+		t.Errorf("expected a ready modem without Loop, got %+v", state)
+	}
+
+	loopDone := make(chan error, 1)
+	go func() {
+		loopDone <- m.Loop(ctx)
+	}()
+
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- m.DeleteSMS(ctx, 3)
+	}()
+
+	<-written
+	state := m.State()
+	if !state.LoopRunning || state.Pending == nil || state.Pending.Command != "AT+CMGD=" {
+		t.Errorf("expected AT+CMGD in flight without its parameters, got %+v", state)
+	}
+	if state.URCCapacity == 0 {
+		t.Errorf("expected the URC channel capacity, got %+v", state)
+	}
+
+	close(answer)
+	if err := <-deleted; err != nil {
+		t.Errorf("unexpected error from DeleteSMS(): %v", err)
+	}
+	eof()
+	<-loopDone
+
+	if state := m.State(); state.LoopRunning || state.Pending != nil || state.LastError == "" {
+		t.Errorf("expected the Loop ended with an error and no command in flight, got %+v", state)
+	}
+}
@@ -0,0 +1,123 @@
+package modem
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"i4.energy/across/smsgw/at"
+	"i4.energy/across/smsgw/pdu"
+)
+
+// StoreSMS writes a text message to the modem's message storage without
+// sending it (AT+CMGW) and returns its storage index. It can be sent later
+// with SendStored, for example to pre-stage the messages of a scheduled
+// blast. Recipient and message are validated like by SendSMS and the
+// sandbox recipient applies.
+//
+// Like SendSMS, messages that text mode would not pass unchanged are
+// written in PDU mode, as GSM 7-bit or UCS-2. A stored message is a single
+// message, text taking more than one fails with ErrMessageTooLong.
+func (m *Modem) StoreSMS(ctx context.Context, recipient, message string) (int, error) {
+	recipient, message, err := m.prepareText(recipient, message)
+	if err != nil {
+		return 0, err
+	}
+
+	var tpdu []byte
+	if !pdu.ASCIICompatible(message) {
+		if parts := pdu.Measure(message).Segments; parts > 1 {
+			return 0, fmt.Errorf("%w: takes %d messages, stored messages take one", ErrMessageTooLong, parts)
+		}
+		tpdus, err := m.encodeMessage(recipient, message, textEncoding(message))
+		if err != nil {
+			return 0, err
+		}
+		tpdu = tpdus[0]
+	}
+
+	// Writing uses the text entry like sending does
+	release, err := m.acquireSubmission(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	var resp string
+	if tpdu != nil {
+		err = m.inPDUMode(ctx, func() error {
+			resp, err = m.write(ctx, at.CMGWPDU(len(tpdu)), "00"+strings.ToUpper(hex.EncodeToString(tpdu)))
+			return err
+		})
+	} else {
+		resp, err = m.write(ctx, at.CMGW(m.encodeText(recipient)), m.encodeText(message))
+	}
+	if err != nil {
+		return 0, err
+	}
+	params, ok := at.Params(resp, at.MsgWritten)
+	if !ok {
+		return 0, fmt.Errorf("unexpected SMS write response: %q", resp)
+	}
+	index, err := strconv.Atoi(params)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected SMS write response: %q", resp)
+	}
+	return index, nil
+}
+
+// write enters body after the prompt of the AT+CMGW command cmd and returns
+// the response.
+func (m *Modem) write(ctx context.Context, cmd, body string) (string, error) {
+	resp, err := m.exec(ctx, cmd)
+	if err != nil {
+		return "", fmt.Errorf("AT+CMGW command failed: %w", err)
+	}
+	if !strings.Contains(resp, at.Prompt) {
+		return "", fmt.Errorf("did not receive SMS prompt, got: %q", resp)
+	}
+
+	// Unlike the text of AT+CMGS, the text is not submitted to the network
+	resp, err = m.execAs(ctx, CommandGeneral, body+at.CtrlZ)
+	if err != nil {
+		m.leavePrompt(ctx)
+		return "", fmt.Errorf("SMS write failed: %w", err)
+	}
+	return resp, nil
+}
+
+// SendStored sends the message stored at index (AT+CMSS), leaving the
+// retransmission at the radio layer to the modem. The message stays in
+// storage, see DeleteSMS. Like SendSMS, it honors the minimum send
+// interval and is held while the modem is paused.
+func (m *Modem) SendStored(ctx context.Context, index int) error {
+	return m.submit(ctx, func() error {
+		start := m.config.clock.Now()
+		resp, err := m.exec(ctx, at.CMSS(index))
+		if err != nil {
+			return fmt.Errorf("AT+CMSS command failed: %w", err)
+		}
+		if !strings.Contains(resp, at.OK) {
+			return fmt.Errorf("unexpected SMS response: %s", resp)
+		}
+		m.observeAcceptance(start)
+		return nil
+	})
+}
+
+// DeleteSMS deletes the message stored at index (AT+CMGD).
+func (m *Modem) DeleteSMS(ctx context.Context, index int) error {
+	// Must not be taken as the text of a message being submitted
+	release, err := m.acquireSubmission(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if _, err := m.exec(ctx, at.CMGD(index)); err != nil {
+		return fmt.Errorf("AT+CMGD command failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestStoredMessages(t *testing.T) {
+	newModem := func(t *testing.T, ctrl *gomock.Controller) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := modem.NewConfigBuilder().
+			WithDialer(mockDialer).
+			WithMinSendInterval(0).
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	t.Run("Store, send and delete", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGW=\"+1234567890\"\r", "> "},
+			Exchange{"Blast\x1a\r", "+CMGW: 7\r\n\r\nOK\r\n"},
+			Exchange{"AT+CMSS=7\r", "+CMSS: 12\r\n\r\nOK\r\n"},
+			Exchange{"AT+CMGD=7\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		index, err := m.StoreSMS(ctx, "+1234567890", "Blast")
+		if err != nil {
+			t.Fatalf("unexpected store error: %v", err)
+		}
+		if index != 7 {
+			t.Errorf("expected index 7, got %d", index)
+		}
+		if err := m.SendStored(ctx, index); err != nil {
+			t.Errorf("unexpected send error: %v", err)
+		}
+		if err := m.DeleteSMS(ctx, index); err != nil {
+			t.Errorf("unexpected delete error: %v", err)
+		}
+		eof()
+	})
+
+	t.Run("Stores text not coded alike in ASCII as PDU", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGF=0\r", "OK\r\n"},
+			Exchange{"AT+CMGW=19\r", "> "},
+			Exchange{"0001000A912143658709000007E3B0B9000AD400\x1a\r", "+CMGW: 7\r\n\r\nOK\r\n"},
+			Exchange{"AT+CMGF=1\r", "OK\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		index, err := m.StoreSMS(ctx, "+1234567890", "café £5")
+		eof()
+		if err != nil {
+			t.Fatalf("unexpected store error: %v", err)
+		}
+		if index != 7 {
+			t.Errorf("expected index 7, got %d", index)
+		}
+	})
+
+	t.Run("Rejects PDU text taking more than one message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		mockTransport.EXPECT().Close().Return(nil)
+		defer m.Close()
+
+		_, err := m.StoreSMS(context.Background(), "+1234567890", strings.Repeat("Ж", 71))
+		if !errors.Is(err, modem.ErrMessageTooLong) {
+			t.Errorf("expected ErrMessageTooLong, got: %v", err)
+		}
+	})
+
+	t.Run("Error on full storage", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMGW=\"+1234567890\"\r", "> "},
+			Exchange{"Blast\x1a\r", "+CMS ERROR: 322\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		if _, err := m.StoreSMS(ctx, "+1234567890", "Blast"); err == nil {
+			t.Error("expected error when storage is full")
+		}
+		eof()
+	})
+
+	t.Run("Error on network rejection of stored message", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newModem(t, ctrl)
+		defer m.Close()
+
+		eof := expectExchanges(mockTransport,
+			Exchange{"AT+CMSS=3\r", "+CMS ERROR: 500\r\n"},
+		)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		ctx := context.Background()
+		go m.Loop(ctx)
+
+		if err := m.SendStored(ctx, 3); err == nil {
+			t.Error("expected error on network rejection")
+		}
+		eof()
+	})
+}
@@ -0,0 +1,67 @@
+package modem
+
+import (
+	"strings"
+	"time"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// CommandClass groups AT commands by how long a modem may take to answer
+// them, see ConfigBuilder.WithCommandTimeout.
+type CommandClass int
+
+const (
+	// CommandGeneral covers the commands answered by the modem itself,
+	// such as queries and settings. They use the AT timeout.
+	CommandGeneral CommandClass = iota
+	// CommandMessage covers submitting a message to the network: AT+CMGS,
+	// the message text ending its text entry and AT+CMSS. The network may
+	// take a minute or more to accept a message on a poor signal. Storing
+	// a message with AT+CMGW is answered by the modem and CommandGeneral.
+	CommandMessage
+	// CommandNetworkScan covers the operator commands (AT+COPS), of which
+	// a scan for available networks takes minutes.
+	CommandNetworkScan
+)
+
+// defaultTimeouts are the timeouts of the command classes other than
+// CommandGeneral, after the maximum response times modem vendors document
+// for them.
+var defaultTimeouts = map[CommandClass]time.Duration{
+	CommandMessage:     120 * time.Second,
+	CommandNetworkScan: 180 * time.Second,
+}
+
+// classOf returns the class of cmd.
+func classOf(cmd string) CommandClass {
+	if strings.HasSuffix(cmd, at.CtrlZ) {
+		return CommandMessage
+	}
+	upper := strings.ToUpper(strings.TrimSpace(cmd))
+	switch {
+	case strings.HasPrefix(upper, "AT+CMGS"), strings.HasPrefix(upper, "AT+CMSS"):
+		return CommandMessage
+	case strings.HasPrefix(upper, "AT+COPS"):
+		return CommandNetworkScan
+	default:
+		return CommandGeneral
+	}
+}
+
+// commandTimeout returns the timeout for cmd, zero if it has none.
+func (c Config) commandTimeout(cmd string) time.Duration {
+	return c.classTimeout(classOf(cmd))
+}
+
+// classTimeout returns the timeout for the commands of class, zero if they
+// have none.
+func (c Config) classTimeout(class CommandClass) time.Duration {
+	if timeout, ok := c.timeouts[class]; ok {
+		return timeout
+	}
+	if timeout, ok := defaultTimeouts[class]; ok {
+		return timeout
+	}
+	return c.atTimeout
+}
@@ -0,0 +1,158 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestCommandTimeouts(t *testing.T) {
+	newLoopingModem := func(t *testing.T, ctrl *gomock.Controller, builder *modem.ConfigBuilder) (*modem.Modem, *modem.MockTransport) {
+		mockTransport := modem.NewMockTransport(ctrl)
+		mockDialer := modem.NewMockDialer(ctrl)
+
+		gomock.InOrder(
+			slices.Concat(
+				[]any{
+					mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+				},
+				initMockCalls(mockTransport),
+			)...,
+		)
+
+		config, err := builder.WithDialer(mockDialer).Build()
+		if err != nil {
+			t.Fatalf("unexpected error from Build(): %v", err)
+		}
+
+		m, err := modem.New(context.Background(), config)
+		if err != nil {
+			t.Fatalf("failed to create modem: %v", err)
+		}
+		return m, mockTransport
+	}
+
+	// slowAcceptance answers the message text only once release is closed,
+	// like a network accepting a message on a poor signal. The returned
+	// channel is closed when the message text was written.
+	slowAcceptance := func(mockTransport *modem.MockTransport, release <-chan struct{}) (<-chan struct{}, func()) {
+		cmgsWritten, bodyWritten := make(chan struct{}), make(chan struct{})
+		mockTransport.EXPECT().Write([]byte(`AT+CMGS="+1234567890"` + "\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(cmgsWritten)
+			return len(p), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-cmgsWritten
+			return copy(p, "> "), nil
+		})
+		mockTransport.EXPECT().Write([]byte("Hello\x1a\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(bodyWritten)
+			return len(p), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-bodyWritten
+			<-release
+			return copy(p, "+CMGS: 7\r\n\r\nOK\r\n"), nil
+		})
+		return bodyWritten, expectExchanges(mockTransport)
+	}
+
+	t.Run("Message submission outlasts the AT timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder().
+			WithATTimeout(20*time.Millisecond))
+		defer m.Close()
+
+		// The network accepts the message only after a status poll queued
+		// behind it ran out of the AT timeout
+		release := make(chan struct{})
+		bodyWritten, eof := slowAcceptance(mockTransport, release)
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		sent := make(chan error, 1)
+		go func() {
+			sent <- m.SendSMS(context.Background(), "+1234567890", "Hello")
+		}()
+		<-bodyWritten
+		if _, err := m.SIMStatus(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the status poll to time out, got: %v", err)
+		}
+		close(release)
+
+		err := <-sent
+		eof()
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Message timeout can be shortened", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder().
+			WithCommandTimeout(modem.CommandMessage, 20*time.Millisecond))
+		defer m.Close()
+
+		// The late answer is discarded after resynchronizing
+		release := make(chan struct{})
+		_, eof := slowAcceptance(mockTransport, release)
+		mockTransport.EXPECT().Write([]byte("AT\r")).Return(3, nil).AnyTimes()
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		err := m.SendSMS(context.Background(), "+1234567890", "Hello")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+		}
+		close(release)
+		eof()
+	})
+
+	t.Run("Storing a message uses the AT timeout", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		m, mockTransport := newLoopingModem(t, ctrl, modem.NewConfigBuilder().
+			WithATTimeout(20*time.Millisecond))
+		defer m.Close()
+
+		// The modem never gets to answer the message text in time
+		cmgwWritten, release := make(chan struct{}), make(chan struct{})
+		mockTransport.EXPECT().Write([]byte(`AT+CMGW="+1234567890"` + "\r")).DoAndReturn(func(p []byte) (int, error) {
+			close(cmgwWritten)
+			return len(p), nil
+		})
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-cmgwWritten
+			return copy(p, "> "), nil
+		})
+		mockTransport.EXPECT().Write([]byte("Hello\x1a\r")).Return(7, nil)
+		mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+			<-release
+			return copy(p, "+CMGW: 7\r\n\r\nOK\r\n"), nil
+		})
+		eof := expectExchanges(mockTransport)
+		mockTransport.EXPECT().Write([]byte("AT\r")).Return(3, nil).AnyTimes()
+		mockTransport.EXPECT().Close().Return(nil)
+
+		go m.Loop(context.Background())
+
+		_, err := m.StoreSMS(context.Background(), "+1234567890", "Hello")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+		}
+		close(release)
+		eof()
+	})
+}
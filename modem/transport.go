@@ -3,6 +3,7 @@ package modem
 import (
 	"context"
 	"io"
+	"time"
 
 	"go.bug.st/serial"
 )
@@ -19,6 +20,18 @@ type Transport interface {
 	io.ReadWriteCloser
 }
 
+// inputFlusher is implemented by transports that can discard the input
+// buffered by the operating system, such as serial.Port.
+type inputFlusher interface {
+	ResetInputBuffer() error
+}
+
+// readTimeouter is implemented by transports whose Read can return after a
+// timeout without data, such as serial.Port. A negative timeout blocks.
+type readTimeouter interface {
+	SetReadTimeout(t time.Duration) error
+}
+
 // Dialer opens a Transport to a GSM modem.
 //
 // Dialer abstracts how the modem connection is created (for example, via a
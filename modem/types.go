@@ -0,0 +1,120 @@
+package modem
+
+import "fmt"
+
+// This file holds the typed results of modem operations. They carry JSON
+// tags so that an HTTP layer can serialize them as they are.
+
+// SignalQuality is the received signal strength and bit error rate as
+// reported by AT+CSQ.
+type SignalQuality struct {
+	// RSSI is the signal strength indication from 0 (-113 dBm or less) to
+	// 31 (-51 dBm or more), 99 if not known
+	RSSI int `json:"rssi"`
+	// BER is the bit error rate class from 0 to 7, 99 if not known
+	BER int `json:"ber"`
+}
+
+// Known reports whether the modem reported a signal strength.
+func (q SignalQuality) Known() bool {
+	return q.RSSI >= 0 && q.RSSI <= 31
+}
+
+// DBm returns the signal strength in dBm, or 0 if it is not known.
+func (q SignalQuality) DBm() int {
+	if !q.Known() {
+		return 0
+	}
+	return -113 + 2*q.RSSI
+}
+
+// RegistrationStatus is the network registration state <stat> of
+// AT+CREG?, with the values of 3GPP TS 27.007.
+type RegistrationStatus int
+
+const (
+	// RegistrationNone means the modem is not registered and not searching
+	RegistrationNone RegistrationStatus = iota
+	// RegistrationHome means the modem is registered to its home network
+	RegistrationHome
+	// RegistrationSearching means the modem is searching for a network
+	RegistrationSearching
+	// RegistrationDenied means the network rejected the registration
+	RegistrationDenied
+	// RegistrationUnknown means the modem could not tell, e.g. out of
+	// coverage
+	RegistrationUnknown
+	// RegistrationRoaming means the modem is registered to a foreign network
+	RegistrationRoaming
+)
+
+var registrationNames = map[RegistrationStatus]string{
+	RegistrationNone:      "not registered",
+	RegistrationHome:      "home",
+	RegistrationSearching: "searching",
+	RegistrationDenied:    "denied",
+	RegistrationUnknown:   "unknown",
+	RegistrationRoaming:   "roaming",
+}
+
+func (s RegistrationStatus) String() string {
+	if name, ok := registrationNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("RegistrationStatus(%d)", int(s))
+}
+
+// Registered reports whether the modem can use the network, at home or
+// roaming.
+func (s RegistrationStatus) Registered() bool {
+	return s == RegistrationHome || s == RegistrationRoaming
+}
+
+// MarshalText encodes the status by its name.
+func (s RegistrationStatus) MarshalText() ([]byte, error) {
+	if _, ok := registrationNames[s]; !ok {
+		return nil, fmt.Errorf("invalid registration status %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a status name as encoded by MarshalText.
+func (s *RegistrationStatus) UnmarshalText(text []byte) error {
+	for status, name := range registrationNames {
+		if name == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid registration status %q", text)
+}
+
+// NetworkStatus is the network registration of the modem as reported by
+// AT+CREG? and AT+COPS?.
+type NetworkStatus struct {
+	Registration RegistrationStatus `json:"registration"`
+	// Operator is the name of the network, empty if not known
+	Operator string `json:"operator,omitempty"`
+	// LAC is the hexadecimal location area code, empty if not reported
+	LAC string `json:"lac,omitempty"`
+	// CellID is the hexadecimal cell ID, empty if not reported
+	CellID string `json:"cell_id,omitempty"`
+}
+
+// DeviceInfo identifies the modem as reported by AT+CGMI, AT+CGMM,
+// AT+CGMR and AT+CGSN.
+type DeviceInfo struct {
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model"`
+	Revision     string `json:"revision"`
+	IMEI         string `json:"imei"`
+}
+
+// DataUsage is the cumulative packet data traffic of the modem as counted
+// by the module, e.g. AT+QGDCNT? on Quectel.
+type DataUsage struct {
+	// Sent is the number of bytes sent
+	Sent uint64 `json:"bytes_sent"`
+	// Received is the number of bytes received
+	Received uint64 `json:"bytes_received"`
+}
@@ -0,0 +1,57 @@
+package modem_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestResultTypes(t *testing.T) {
+	t.Run("Serializes states by name", func(t *testing.T) {
+		status := struct {
+			SIM     modem.SIMState      `json:"sim"`
+			Network modem.NetworkStatus `json:"network"`
+		}{
+			SIM:     modem.SIMPINRequired,
+			Network: modem.NetworkStatus{Registration: modem.RegistrationRoaming, LAC: "1A2B"},
+		}
+
+		b, err := json.Marshal(status)
+		if err != nil {
+			t.Fatalf("unexpected error from Marshal(): %v", err)
+		}
+		want := `{"sim":"PIN required","network":{"registration":"roaming","lac":"1A2B"}}`
+		if string(b) != want {
+			t.Errorf("expected %s, got %s", want, b)
+		}
+
+		var decoded struct {
+			SIM     modem.SIMState      `json:"sim"`
+			Network modem.NetworkStatus `json:"network"`
+		}
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("unexpected error from Unmarshal(): %v", err)
+		}
+		if decoded.SIM != status.SIM || decoded.Network != status.Network {
+			t.Errorf("expected %+v after round trip, got %+v", status, decoded)
+		}
+	})
+
+	t.Run("Signal strength in dBm", func(t *testing.T) {
+		tests := []struct {
+			rssi int
+			dbm  int
+		}{
+			{rssi: 0, dbm: -113},
+			{rssi: 15, dbm: -83},
+			{rssi: 31, dbm: -51},
+			{rssi: 99, dbm: 0},
+		}
+		for _, tt := range tests {
+			if got := (modem.SignalQuality{RSSI: tt.rssi, BER: 99}).DBm(); got != tt.dbm {
+				t.Errorf("RSSI %d: expected %d dBm, got %d", tt.rssi, tt.dbm, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package modem
+
+import (
+	"strings"
+	"time"
+)
+
+// urcLimit is a rate limit for the URCs starting with prefix.
+type urcLimit struct {
+	prefix   string
+	interval time.Duration
+}
+
+// urcLimiter coalesces URC floods: of the URCs matching a limit, one is
+// delivered per interval and the others are held back, only the latest
+// of them is delivered once the interval passed. It is only used by the
+// Loop goroutine.
+type urcLimiter struct {
+	limits []urcLimit
+	// last is when a URC of each limit was delivered
+	last map[string]time.Time
+	// held is the latest URC of each limit waiting for its interval
+	held map[string]string
+}
+
+// newURCLimiter returns a limiter for limits, nil if there are none.
+func newURCLimiter(limits []urcLimit) *urcLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	return &urcLimiter{
+		limits: limits,
+		last:   make(map[string]time.Time),
+		held:   make(map[string]string),
+	}
+}
+
+// admit reports whether urc may be delivered now. Otherwise it is held
+// back, and the URC it replaces, if any, is returned as suppressed.
+func (l *urcLimiter) admit(urc string, now time.Time) (ok bool, suppressed string) {
+	limit, found := l.match(urc)
+	if !found {
+		return true, ""
+	}
+	if last, seen := l.last[limit.prefix]; !seen || now.Sub(last) >= limit.interval {
+		l.last[limit.prefix] = now
+		// A held URC is older than this one
+		suppressed = l.held[limit.prefix]
+		delete(l.held, limit.prefix)
+		return true, suppressed
+	}
+	suppressed = l.held[limit.prefix]
+	l.held[limit.prefix] = urc
+	return false, suppressed
+}
+
+// due returns the held URCs whose interval passed, to be delivered now.
+func (l *urcLimiter) due(now time.Time) []string {
+	var urcs []string
+	for _, limit := range l.limits {
+		urc, held := l.held[limit.prefix]
+		if held && now.Sub(l.last[limit.prefix]) >= limit.interval {
+			urcs = append(urcs, urc)
+			l.last[limit.prefix] = now
+			delete(l.held, limit.prefix)
+		}
+	}
+	return urcs
+}
+
+// next returns the time until the next held URC is due. ok is false if no
+// URC is held.
+func (l *urcLimiter) next(now time.Time) (d time.Duration, ok bool) {
+	for _, limit := range l.limits {
+		if _, held := l.held[limit.prefix]; !held {
+			continue
+		}
+		wait := max(l.last[limit.prefix].Add(limit.interval).Sub(now), 0)
+		if !ok || wait < d {
+			d, ok = wait, true
+		}
+	}
+	return d, ok
+}
+
+// match returns the first limit whose prefix urc starts with.
+func (l *urcLimiter) match(urc string) (urcLimit, bool) {
+	for _, limit := range l.limits {
+		if strings.HasPrefix(urc, limit.prefix) {
+			return limit, true
+		}
+	}
+	return urcLimit{}, false
+}
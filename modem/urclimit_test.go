@@ -0,0 +1,93 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/at"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestURCRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTransport := modem.NewMockTransport(ctrl)
+	mockDialer := modem.NewMockDialer(ctrl)
+
+	gomock.InOrder(
+		slices.Concat(
+			[]any{
+				mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+			},
+			initMockCalls(mockTransport),
+		)...,
+	)
+
+	clock := newFakeClock()
+	config, err := modem.NewConfigBuilder().
+		WithDialer(mockDialer).
+		WithClock(clock).
+		WithClassifier(at.PrefixClassifier{
+			Prefixes: map[string]at.ResponseType{"+CSQ:": at.TypeURC},
+		}).
+		WithURCRateLimit("+CSQ:", time.Second).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error from Build(): %v", err)
+	}
+
+	ctx := context.Background()
+	m, err := modem.New(ctx, config)
+	if err != nil {
+		t.Fatalf("failed to create modem: %v", err)
+	}
+	defer m.Close()
+
+	// A signal URC flood with a new message notification in between
+	mockTransport.EXPECT().Read(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+		return copy(p, "+CSQ: 10,99\r\n+CSQ: 11,99\r\n+CSQ: 12,99\r\n+CMTI: \"SM\",1\r\n"), nil
+	})
+	eof := expectExchanges(mockTransport)
+	mockTransport.EXPECT().Close().Return(nil)
+
+	loopDone := make(chan error, 1)
+	go func() {
+		loopDone <- m.Loop(ctx)
+	}()
+
+	receive := func() string {
+		select {
+		case urc := <-m.URC():
+			return urc
+		case <-time.After(time.Second):
+			t.Fatal("expected URC to be received within timeout")
+			return ""
+		}
+	}
+
+	// The first signal URC passes, the others wait for the interval
+	for _, want := range []string{"+CSQ: 10,99", `+CMTI: "SM",1`} {
+		if got := receive(); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+	clock.WaitForWaiter()
+	clock.Advance(time.Second)
+	if got := receive(); got != "+CSQ: 12,99" {
+		t.Errorf("expected the latest signal URC, got %q", got)
+	}
+
+	eof()
+	if err := <-loopDone; !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got: %v", err)
+	}
+	if got := m.Diagnostics()[modem.EventURCSuppressed]; got != 1 {
+		t.Errorf("expected 1 suppressed URC, got %d", got)
+	}
+}
@@ -0,0 +1,71 @@
+package modem
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"i4.energy/across/smsgw/at"
+)
+
+// dataCounters are the vendor commands reporting the data usage, tried in
+// order. sent and received are the indexes of the byte counts in the
+// response, given in base.
+var dataCounters = []struct {
+	cmd      string
+	prefix   string
+	sent     int
+	received int
+	base     int
+}{
+	// Quectel: +QGDCNT: <bytes_sent>,<bytes_recv>
+	{cmd: at.CmdDataCount, prefix: at.DataCount, sent: 0, received: 1, base: 10},
+	// Huawei: ^DSFLOWQRY: <last_ds_time>,<last_tx_flow>,<last_rx_flow>,
+	// <total_ds_time>,<total_tx_flow>,<total_rx_flow> in hexadecimal
+	{cmd: at.CmdDataFlow, prefix: at.DataFlow, sent: 4, received: 5, base: 16},
+}
+
+// DataUsage queries the cumulative packet data traffic counted by the
+// modem and reports it to the metrics. It returns ErrNotSupported if the
+// modem supports none of the known counter commands.
+func (m *Modem) DataUsage(ctx context.Context) (DataUsage, error) {
+	for _, counter := range dataCounters {
+		resp, err := m.execTelemetry(ctx, counter.cmd)
+		if err != nil {
+			if !isErrorResponse(err) {
+				return DataUsage{}, fmt.Errorf("query data usage: %w", err)
+			}
+			// Not supported by this modem
+			continue
+		}
+		params, found := at.Params(resp, counter.prefix)
+		if !found {
+			continue
+		}
+		fields := at.SplitFields(params)
+		if max(counter.sent, counter.received) >= len(fields) {
+			return DataUsage{}, fmt.Errorf("unexpected data usage response: %q", resp)
+		}
+		sent, err := strconv.ParseUint(fields[counter.sent], counter.base, 64)
+		if err != nil {
+			return DataUsage{}, fmt.Errorf("unexpected data usage response: %q", resp)
+		}
+		received, err := strconv.ParseUint(fields[counter.received], counter.base, 64)
+		if err != nil {
+			return DataUsage{}, fmt.Errorf("unexpected data usage response: %q", resp)
+		}
+
+		m.config.metrics.SetGauge(MetricDataSent, float64(sent))
+		m.config.metrics.SetGauge(MetricDataReceived, float64(received))
+		return DataUsage{Sent: sent, Received: received}, nil
+	}
+	return DataUsage{}, fmt.Errorf("query data usage: %w", ErrNotSupported)
+}
+
+// isErrorResponse reports whether a command failed because the modem
+// answered it with an error result code, e.g. for an unknown command.
+func isErrorResponse(err error) bool {
+	msg := err.Error()
+	return msg == at.ERROR || strings.HasPrefix(msg, at.CmeError)
+}
@@ -0,0 +1,105 @@
+package modem_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	"i4.energy/across/smsgw/modem"
+)
+
+func TestDataUsage(t *testing.T) {
+	tests := []struct {
+		name      string
+		exchanges []Exchange
+		expected  modem.DataUsage
+		wantErr   error
+	}{
+		{
+			name: "Quectel counters",
+			exchanges: []Exchange{
+				{"AT+QGDCNT?\r", "+QGDCNT: 1024,204800\r\n\r\nOK\r\n"},
+			},
+			expected: modem.DataUsage{Sent: 1024, Received: 204800},
+		},
+		{
+			name: "Huawei flow report",
+			exchanges: []Exchange{
+				{"AT+QGDCNT?\r", "ERROR\r\n"},
+				{"AT^DSFLOWQRY\r", "^DSFLOWQRY:0000000A,0000000000000100,0000000000000200,00000E10,0000000000000400,0000000000032000\r\n\r\nOK\r\n"},
+			},
+			expected: modem.DataUsage{Sent: 0x400, Received: 0x32000},
+		},
+		{
+			name: "No counters",
+			exchanges: []Exchange{
+				{"AT+QGDCNT?\r", "ERROR\r\n"},
+				{"AT^DSFLOWQRY\r", "+CME ERROR: operation not supported\r\n"},
+			},
+			wantErr: modem.ErrNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockTransport := modem.NewMockTransport(ctrl)
+			mockDialer := modem.NewMockDialer(ctrl)
+
+			gomock.InOrder(
+				slices.Concat(
+					[]any{
+						mockDialer.EXPECT().Dial(gomock.Any()).Return(mockTransport, nil),
+					},
+					initMockCalls(mockTransport),
+				)...,
+			)
+
+			metrics := &recordingMetrics{values: map[string]float64{}}
+			config, err := modem.NewConfigBuilder().
+				WithDialer(mockDialer).
+				WithMetrics(metrics).
+				Build()
+			if err != nil {
+				t.Fatalf("unexpected error from Build(): %v", err)
+			}
+
+			m, err := modem.New(context.Background(), config)
+			if err != nil {
+				t.Fatalf("failed to create modem: %v", err)
+			}
+			defer m.Close()
+
+			eof := expectExchanges(mockTransport, tt.exchanges...)
+			mockTransport.EXPECT().Close().Return(nil)
+
+			ctx := context.Background()
+			go m.Loop(ctx)
+
+			usage, err := m.DataUsage(ctx)
+			eof()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if usage != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, usage)
+			}
+			if got := metrics.get(modem.MetricDataSent); got != float64(tt.expected.Sent) {
+				t.Errorf("expected %s %d, got %v", modem.MetricDataSent, tt.expected.Sent, got)
+			}
+			if got := metrics.get(modem.MetricDataReceived); got != float64(tt.expected.Received) {
+				t.Errorf("expected %s %d, got %v", modem.MetricDataReceived, tt.expected.Received, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package pdu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Alphabet is the character set of the user data, as selected by the
+// TP-DCS octet.
+type Alphabet int
+
+const (
+	// Alphabet7Bit is the GSM 7-bit default alphabet
+	Alphabet7Bit Alphabet = iota
+	// Alphabet8Bit is binary data
+	Alphabet8Bit
+	// AlphabetUCS2 is UCS-2 (UTF-16 big endian) text
+	AlphabetUCS2
+)
+
+const (
+	// mtiMask selects the message type indicator of the first octet
+	mtiMask byte = 0x03
+	// mtiDeliver is the message type indicator for SMS-DELIVER
+	mtiDeliver byte = 0x00
+)
+
+var (
+	// ErrMalformedPDU is returned when a TPDU is truncated or its fields
+	// are inconsistent.
+	ErrMalformedPDU = errors.New("malformed PDU")
+
+	// ErrUnsupportedPDU is returned for TPDUs that are valid but cannot be
+	// decoded, such as other message types or compressed user data.
+	ErrUnsupportedPDU = errors.New("unsupported PDU")
+)
+
+// Deliver is a decoded incoming SMS-DELIVER TPDU.
+type Deliver struct {
+	// SMSC is the service center that delivered the message, if present
+	SMSC string
+	// Sender is the originating address. Alphanumeric senders, such as a
+	// company name, are decoded to text.
+	Sender string
+	// PID is the TP-PID octet
+	PID byte
+	// DCS is the TP-DCS octet
+	DCS byte
+	// Alphabet is the character set of the user data, derived from DCS
+	Alphabet Alphabet
+	// Time is the service center time stamp, in the time zone it was
+	// given in
+	Time time.Time
+	// UDH holds the user data header information elements, without the
+	// leading length octet. It is empty if the message has no header.
+	UDH []byte
+	// Text is the message text for the 7-bit and UCS-2 alphabets
+	Text string
+	// UserData is the payload following the header for the 8-bit alphabet
+	UserData []byte
+}
+
+// DecodeDeliver decodes an SMS-DELIVER as read with AT+CMGR or AT+CMGL in
+// PDU mode: the SMSC field followed by the TPDU, hex decoded.
+func DecodeDeliver(b []byte) (Deliver, error) {
+	var d Deliver
+	r := reader{b: b}
+
+	smscLen := int(r.byte())
+	if smscLen > 0 {
+		smsc := r.bytes(smscLen)
+		if r.err == nil {
+			d.SMSC = decodeNumber(smsc[0], smsc[1:], 2*(smscLen-1))
+		}
+	}
+
+	firstOctet := r.byte()
+	if r.err == nil && firstOctet&mtiMask != mtiDeliver {
+		return Deliver{}, fmt.Errorf("%w: message type %d", ErrUnsupportedPDU, firstOctet&mtiMask)
+	}
+
+	// TP-OA: the length counts semi-octets, not octets
+	digits := int(r.byte())
+	toa := r.byte()
+	addr := r.bytes((digits + 1) / 2)
+	d.PID = r.byte()
+	d.DCS = r.byte()
+	scts := r.bytes(sctsLength)
+	udl := int(r.byte())
+	if r.err != nil {
+		return Deliver{}, r.err
+	}
+	t, err := DecodeSCTS(scts)
+	if err != nil {
+		return Deliver{}, err
+	}
+	d.Time = t
+	d.Sender = decodeAddress(toa, addr, digits)
+
+	alphabet, err := alphabetOf(d.DCS)
+	if err != nil {
+		return Deliver{}, err
+	}
+	d.Alphabet = alphabet
+
+	// The rest of the TPDU is the user data, its length depends on the
+	// alphabet
+	ud := r.rest()
+	udOctets := udl
+	if alphabet == Alphabet7Bit {
+		udOctets = (udl*7 + 7) / 8
+	}
+	if len(ud) < udOctets {
+		return Deliver{}, fmt.Errorf("%w: user data truncated", ErrMalformedPDU)
+	}
+	ud = ud[:udOctets]
+
+	// headerOctets is the length of the user data header including its
+	// length octet
+	headerOctets := 0
+	if firstOctet&flagUDHI != 0 {
+		if len(ud) == 0 || 1+int(ud[0]) > len(ud) {
+			return Deliver{}, fmt.Errorf("%w: user data header truncated", ErrMalformedPDU)
+		}
+		headerOctets = 1 + int(ud[0])
+		d.UDH = ud[1:headerOctets]
+	}
+
+	switch alphabet {
+	case Alphabet7Bit:
+		// The text starts at the septet boundary after the header
+		headerSeptets := (headerOctets*8 + 6) / 7
+		if udl < headerSeptets {
+			return Deliver{}, fmt.Errorf("%w: user data header longer than user data", ErrMalformedPDU)
+		}
+		d.Text = decodeGSM7(unpackSeptets(ud, headerSeptets*7, udl-headerSeptets))
+	case Alphabet8Bit:
+		d.UserData = ud[headerOctets:]
+	case AlphabetUCS2:
+		text := ud[headerOctets:]
+		if len(text)%2 != 0 {
+			return Deliver{}, fmt.Errorf("%w: odd UCS-2 length", ErrMalformedPDU)
+		}
+		units := make([]uint16, len(text)/2)
+		for i := range units {
+			units[i] = uint16(text[2*i])<<8 | uint16(text[2*i+1])
+		}
+		d.Text = string(utf16.Decode(units))
+	}
+	return d, nil
+}
+
+// Concat returns the concatenation information if the message is a part
+// of a concatenated message.
+func (d Deliver) Concat() (Concat, bool) {
+	return ParseConcat(d.UDH)
+}
+
+// alphabetOf returns the alphabet selected by a TP-DCS octet
+// (3GPP TS 23.038 4).
+func alphabetOf(dcs byte) (Alphabet, error) {
+	switch {
+	case dcs&0xC0 == 0x00, dcs&0xC0 == 0x40:
+		// General data coding, possibly marked for automatic deletion
+		if dcs&0x20 != 0 {
+			return 0, fmt.Errorf("%w: compressed user data", ErrUnsupportedPDU)
+		}
+		switch dcs & 0x0C {
+		case 0x04:
+			return Alphabet8Bit, nil
+		case 0x08:
+			return AlphabetUCS2, nil
+		default:
+			return Alphabet7Bit, nil
+		}
+	case dcs&0xF0 == 0xC0, dcs&0xF0 == 0xD0:
+		// Message waiting indication, default alphabet
+		return Alphabet7Bit, nil
+	case dcs&0xF0 == 0xE0:
+		// Message waiting indication, UCS-2
+		return AlphabetUCS2, nil
+	case dcs&0xF0 == 0xF0:
+		// Data coding and message class
+		if dcs&0x04 != 0 {
+			return Alphabet8Bit, nil
+		}
+		return Alphabet7Bit, nil
+	default:
+		return 0, fmt.Errorf("%w: reserved data coding scheme 0x%02X", ErrUnsupportedPDU, dcs)
+	}
+}
+
+// decodeAddress decodes an address field given its type of address, the
+// value octets and the number of semi-octets used.
+func decodeAddress(toa byte, value []byte, digits int) string {
+	if toa&0x70 == 0x50 {
+		// Alphanumeric, GSM 7-bit packed
+		return decodeGSM7(unpackSeptets(value, 0, digits*4/7))
+	}
+	return decodeNumber(toa, value, digits)
+}
+
+// decodeNumber decodes swapped semi-octets into a number, prefixed with
+// "+" for the international type of number. A filler nibble ends it.
+func decodeNumber(toa byte, value []byte, digits int) string {
+	var b strings.Builder
+	if toa&0x70 == 0x10 {
+		b.WriteByte('+')
+	}
+	for i := 0; i < digits && i/2 < len(value); i++ {
+		nibble := value[i/2] & 0x0F
+		if i%2 == 1 {
+			nibble = value[i/2] >> 4
+		}
+		if nibble == 0x0F {
+			break
+		}
+		b.WriteByte("0123456789*#abc"[nibble])
+	}
+	return b.String()
+}
+
+// reader consumes a TPDU octet by octet and remembers if it ran short.
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.b) {
+		r.err = fmt.Errorf("%w: truncated", ErrMalformedPDU)
+		return nil
+	}
+	b := r.b[:n:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *reader) rest() []byte {
+	b := r.b
+	r.b = nil
+	return b
+}
@@ -0,0 +1,187 @@
+package pdu_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+func TestDecodeDeliver(t *testing.T) {
+	tests := []struct {
+		name     string
+		pdu      string
+		sender   string
+		alphabet pdu.Alphabet
+		text     string
+		data     []byte
+		concat   *pdu.Concat
+	}{
+		{
+			name:     "GSM 7-bit with extension characters",
+			pdu:      "0791039624910000040C9103962143658700004210512134008011D07A1B0EA28136BCF77AE3036DCA35",
+			sender:   "+306912345678",
+			alphabet: pdu.Alphabet7Bit,
+			text:     "Pump 4 [ok] €5",
+		},
+		{
+			name:     "UCS-2",
+			pdu:      "0791039624910000040C9103962143658700084210512134008014039103BD03C403BB03AF03B10020003400202713",
+			sender:   "+306912345678",
+			alphabet: pdu.AlphabetUCS2,
+			text:     "Αντλία 4 ✓",
+		},
+		{
+			name:     "Alphanumeric sender",
+			pdu:      "0791039624910000040DD049B7F93D6D4E0100004210512134008002C834",
+			sender:   "InfoSMS",
+			alphabet: pdu.Alphabet7Bit,
+			text:     "Hi",
+		},
+		{
+			name:     "Concatenated GSM 7-bit with fill bits",
+			pdu:      "0791039624910000440C910396214365870000421051213400800F0500032A0201A061391DF4769701",
+			sender:   "+306912345678",
+			alphabet: pdu.Alphabet7Bit,
+			text:     "Part one",
+			concat:   &pdu.Concat{Ref: 0x2A, Total: 2, Seq: 1},
+		},
+		{
+			name:     "Concatenated UCS-2 with 16-bit reference",
+			pdu:      "0791039624910000440C910396214365870008421051213400800D0608041234020203B403CD03BF",
+			sender:   "+306912345678",
+			alphabet: pdu.AlphabetUCS2,
+			text:     "δύο",
+			concat:   &pdu.Concat{Ref: 0x1234, Total: 2, Seq: 2},
+		},
+		{
+			name:     "8-bit data from national number",
+			pdu:      "07910396249100000405812143F5000442105121340080030102FF",
+			sender:   "12345",
+			alphabet: pdu.Alphabet8Bit,
+			data:     []byte{0x01, 0x02, 0xFF},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.pdu)
+			if err != nil {
+				t.Fatalf("invalid test PDU: %v", err)
+			}
+			msg, err := pdu.DecodeDeliver(b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if msg.SMSC != "+306942190000" {
+				t.Errorf("expected SMSC +306942190000, got %q", msg.SMSC)
+			}
+			sent := time.Date(2024, 1, 15, 12, 43, 0, 0, time.FixedZone("", 2*60*60))
+			if !msg.Time.Equal(sent) {
+				t.Errorf("expected time %s, got %s", sent, msg.Time)
+			}
+			if msg.Sender != tt.sender {
+				t.Errorf("expected sender %q, got %q", tt.sender, msg.Sender)
+			}
+			if msg.Alphabet != tt.alphabet {
+				t.Errorf("expected alphabet %d, got %d", tt.alphabet, msg.Alphabet)
+			}
+			if msg.Text != tt.text {
+				t.Errorf("expected text %q, got %q", tt.text, msg.Text)
+			}
+			if !bytes.Equal(msg.UserData, tt.data) {
+				t.Errorf("expected data %X, got %X", tt.data, msg.UserData)
+			}
+
+			concat, ok := msg.Concat()
+			switch {
+			case tt.concat == nil && ok:
+				t.Errorf("expected no concatenation, got %+v", concat)
+			case tt.concat != nil && (!ok || concat != *tt.concat):
+				t.Errorf("expected concatenation %+v, got %+v (%v)", *tt.concat, concat, ok)
+			}
+		})
+	}
+}
+
+func TestDecodeDeliverErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		pdu      string
+		expected error
+	}{
+		{name: "Empty", pdu: "", expected: pdu.ErrMalformedPDU},
+		{name: "Truncated address", pdu: "00040C91039621", expected: pdu.ErrMalformedPDU},
+		{name: "Truncated user data", pdu: "000405812143F5000442105121340080050102", expected: pdu.ErrMalformedPDU},
+		{name: "Header longer than user data", pdu: "004405812143F50004421051213400800209FF", expected: pdu.ErrMalformedPDU},
+		{name: "Header longer than 7-bit user data", pdu: "0040029121000042105101030080" + "0100", expected: pdu.ErrMalformedPDU},
+		{name: "SMS-SUBMIT", pdu: "00" + "01000A912143658709000402" + "6869", expected: pdu.ErrUnsupportedPDU},
+		{name: "Compressed user data", pdu: "000405812143F50020421051213400800100", expected: pdu.ErrUnsupportedPDU},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.pdu)
+			if err != nil {
+				t.Fatalf("invalid test PDU: %v", err)
+			}
+			if _, err := pdu.DecodeDeliver(b); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestDecodeSCTS(t *testing.T) {
+	tests := []struct {
+		name   string
+		scts   string
+		offset time.Duration
+	}{
+		{name: "Positive zone", scts: "42105121340080", offset: 2 * time.Hour},
+		{name: "Negative zone", scts: "42105121340088", offset: -2 * time.Hour},
+		{name: "Negative zone with tens", scts: "4210512134002A", offset: -(5*time.Hour + 30*time.Minute)},
+		{name: "Positive zone with tens", scts: "42105121340032", offset: 5*time.Hour + 45*time.Minute},
+		{name: "UTC", scts: "42105121340000", offset: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tt.scts)
+			if err != nil {
+				t.Fatalf("invalid test time stamp: %v", err)
+			}
+			got, err := pdu.DecodeSCTS(b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// 24/01/15 12:43:00 local time of the service center
+			expected := time.Date(2024, 1, 15, 12, 43, 0, 0, time.UTC).Add(-tt.offset)
+			if !got.Equal(expected) {
+				t.Errorf("expected %s, got %s", expected, got)
+			}
+			if _, offset := got.Zone(); time.Duration(offset)*time.Second != tt.offset {
+				t.Errorf("expected offset %s, got %ds", tt.offset, offset)
+			}
+		})
+	}
+
+	t.Run("ErrMalformedPDU on invalid digits", func(t *testing.T) {
+		b, _ := hex.DecodeString("4210512134A080")
+		if _, err := pdu.DecodeSCTS(b); !errors.Is(err, pdu.ErrMalformedPDU) {
+			t.Errorf("expected ErrMalformedPDU, got: %v", err)
+		}
+	})
+
+	t.Run("ErrMalformedPDU on invalid month", func(t *testing.T) {
+		b, _ := hex.DecodeString("42315121340080")
+		if _, err := pdu.DecodeSCTS(b); !errors.Is(err, pdu.ErrMalformedPDU) {
+			t.Errorf("expected ErrMalformedPDU, got: %v", err)
+		}
+	})
+}
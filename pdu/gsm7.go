@@ -0,0 +1,152 @@
+package pdu
+
+import (
+	"slices"
+	"strings"
+)
+
+// gsm7Escape switches to the extension table for the next septet.
+const gsm7Escape = 0x1B
+
+// gsm7Basic is the GSM 7-bit default alphabet (3GPP TS 23.038 6.2.1).
+var gsm7Basic = [128]rune{
+	'@', '£', '$', '¥', 'è', 'é', 'ù', 'ì', 'ò', 'Ç', '\n', 'Ø', 'ø', '\r', 'Å', 'å',
+	'Δ', '_', 'Φ', 'Γ', 'Λ', 'Ω', 'Π', 'Ψ', 'Σ', 'Θ', 'Ξ', '\x1b', 'Æ', 'æ', 'ß', 'É',
+	' ', '!', '"', '#', '¤', '%', '&', '\'', '(', ')', '*', '+', ',', '-', '.', '/',
+	'0', '1', '2', '3', '4', '5', '6', '7', '8', '9', ':', ';', '<', '=', '>', '?',
+	'¡', 'A', 'B', 'C', 'D', 'E', 'F', 'G', 'H', 'I', 'J', 'K', 'L', 'M', 'N', 'O',
+	'P', 'Q', 'R', 'S', 'T', 'U', 'V', 'W', 'X', 'Y', 'Z', 'Ä', 'Ö', 'Ñ', 'Ü', '§',
+	'¿', 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o',
+	'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', 'ä', 'ö', 'ñ', 'ü', 'à',
+}
+
+// gsm7Extension is the default alphabet extension table, reached through
+// the escape septet (3GPP TS 23.038 6.2.1.1).
+var gsm7Extension = map[byte]rune{
+	0x0A: '\f',
+	0x14: '^',
+	0x28: '{',
+	0x29: '}',
+	0x2F: '\\',
+	0x3C: '[',
+	0x3D: '~',
+	0x3E: ']',
+	0x40: '|',
+	0x65: '€',
+}
+
+// gsm7Codes maps the characters of the default alphabet and its extension
+// table to their septets.
+var gsm7Codes = func() map[rune][]byte {
+	codes := make(map[rune][]byte, len(gsm7Basic)+len(gsm7Extension))
+	for c, r := range gsm7Basic {
+		if c != gsm7Escape {
+			codes[r] = []byte{byte(c)}
+		}
+	}
+	for c, r := range gsm7Extension {
+		codes[r] = []byte{gsm7Escape, c}
+	}
+	return codes
+}()
+
+// SplitGSM7 encodes text as GSM 7-bit septets, one per octet as
+// Submit.UserData takes them with DCS7Bit. Like SplitUCS2 it splits text
+// into the parts of a concatenated message if it does not fit into a single
+// one, without splitting an escape sequence between parts. Characters
+// outside of the alphabet, see Measure, are replaced by '?'.
+func SplitGSM7(text string) [][]byte {
+	var chars [][]byte
+	total := 0
+	for _, r := range text {
+		code, ok := gsm7Codes[r]
+		if !ok {
+			code = gsm7Codes['?']
+		}
+		chars = append(chars, code)
+		total += len(code)
+	}
+	if total <= septetsSingle {
+		return [][]byte{slices.Concat(chars...)}
+	}
+
+	var parts [][]byte
+	var part []byte
+	for _, code := range chars {
+		if len(part)+len(code) > septetsConcat {
+			parts = append(parts, part)
+			part = nil
+		}
+		part = append(part, code...)
+	}
+	return append(parts, part)
+}
+
+// ASCIICompatible reports whether every character of text has the same code
+// in the GSM 7-bit default alphabet as in ASCII. Only such text passes a
+// modem in text mode unchanged, others like 'é', '£' or '@' need to be sent
+// in PDU mode.
+func ASCIICompatible(text string) bool {
+	for _, r := range text {
+		if r >= 0x80 || gsm7Basic[r] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// packSeptets packs septets into octets, starting skip bits into the first
+// octet. It is the reverse of unpackSeptets.
+func packSeptets(septets []byte, skip int) []byte {
+	packed := make([]byte, (skip+7*len(septets)+7)/8)
+	for i, septet := range septets {
+		bit := skip + i*7
+		idx, shift := bit/8, bit%8
+		packed[idx] |= septet << shift
+		if shift > 1 {
+			packed[idx+1] |= septet >> (8 - shift)
+		}
+	}
+	return packed
+}
+
+// decodeGSM7 converts unpacked septets to text. An escaped septet missing
+// from the extension table is shown as its default alphabet character, as
+// the specification requires.
+func decodeGSM7(septets []byte) string {
+	var b strings.Builder
+	for i := 0; i < len(septets); i++ {
+		c := septets[i] & 0x7F
+		if c == gsm7Escape && i+1 < len(septets) {
+			i++
+			next := septets[i] & 0x7F
+			if r, ok := gsm7Extension[next]; ok {
+				b.WriteRune(r)
+			} else {
+				b.WriteRune(gsm7Basic[next])
+			}
+			continue
+		}
+		b.WriteRune(gsm7Basic[c])
+	}
+	return b.String()
+}
+
+// unpackSeptets extracts count septets packed into data, starting skip bits
+// into it. Septets beyond the end of data are not returned.
+func unpackSeptets(data []byte, skip, count int) []byte {
+	septets := make([]byte, 0, count)
+	for i := range count {
+		bit := skip + i*7
+		idx, shift := bit/8, bit%8
+		if idx >= len(data) {
+			break
+		}
+		v := uint16(data[idx]) >> shift
+		if shift > 1 && idx+1 < len(data) {
+			v |= uint16(data[idx+1]) << (8 - shift)
+		}
+		septets = append(septets, byte(v&0x7F))
+	}
+	return septets
+}
@@ -0,0 +1,63 @@
+package pdu_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+func TestSplitGSM7(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		sizes []int
+	}{
+		{name: "Single message", text: strings.Repeat("a", 160), sizes: []int{160}},
+		{name: "Concatenated message", text: strings.Repeat("a", 161), sizes: []int{153, 8}},
+		{name: "Escape sequence moves to the next part", text: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10), sizes: []int{152, 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sizes []int
+			for _, part := range pdu.SplitGSM7(tt.text) {
+				sizes = append(sizes, len(part))
+			}
+			if !slices.Equal(sizes, tt.sizes) {
+				t.Errorf("expected parts of %v septets, got %v", tt.sizes, sizes)
+			}
+		})
+	}
+
+	t.Run("Codes differing from ASCII", func(t *testing.T) {
+		parts := pdu.SplitGSM7("@£$_é€Ж")
+		expected := []byte{0x00, 0x01, 0x02, 0x11, 0x05, 0x1B, 0x65, '?'}
+		if len(parts) != 1 || !bytes.Equal(parts[0], expected) {
+			t.Errorf("expected % X, got % X", expected, parts)
+		}
+	})
+}
+
+func TestASCIICompatible(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{text: "Hello, world!\n", expected: true},
+		{text: "café", expected: false},
+		{text: "£5", expected: false},
+		{text: "mail@example.com", expected: false},
+		{text: "a_b", expected: false},
+		{text: "[x]", expected: false},
+		{text: "", expected: true},
+	}
+
+	for _, tt := range tests {
+		if got := pdu.ASCIICompatible(tt.text); got != tt.expected {
+			t.Errorf("ASCIICompatible(%q) = %v, expected %v", tt.text, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package pdu
+
+import "unicode/utf16"
+
+// Characters available for text in a single message and in each part of a
+// concatenated one, which loses room to the concatenation header.
+const (
+	septetsSingle = 160
+	septetsConcat = 153
+	ucs2Single    = 70
+	ucs2Concat    = 67
+)
+
+// gsm7Septets maps the characters of the GSM 7-bit default alphabet and its
+// extension table to the number of septets they take.
+var gsm7Septets = func() map[rune]int {
+	septets := make(map[rune]int, len(gsm7Basic)+len(gsm7Extension))
+	for c, r := range gsm7Basic {
+		if c != gsm7Escape {
+			septets[r] = 1
+		}
+	}
+	for _, r := range gsm7Extension {
+		septets[r] = 2
+	}
+	return septets
+}()
+
+// Length describes how a text is sent: its alphabet and how many messages
+// it takes.
+type Length struct {
+	// Alphabet is Alphabet7Bit if every character is in the GSM 7-bit
+	// default alphabet or its extension table, AlphabetUCS2 otherwise
+	Alphabet Alphabet
+	// Units is the length of the text in septets for Alphabet7Bit and in
+	// UTF-16 code units for AlphabetUCS2
+	Units int
+	// PerSegment is the number of units that fit into one message, less
+	// if the text needs to be concatenated
+	PerSegment int
+	// Segments is the number of messages the text is sent in
+	Segments int
+	// Escaped lists the characters of the extension table, such as '€' or
+	// '[', that take two septets each
+	Escaped []rune
+	// NonGSM lists the characters outside of the GSM 7-bit alphabet that
+	// made the text use UCS-2
+	NonGSM []rune
+}
+
+// Measure calculates how text is sent as SMS. Characters are never split
+// between two parts, so an escape sequence or a surrogate pair at the end of
+// a part moves to the next one, which can take an extra message.
+func Measure(text string) Length {
+	var l Length
+	for _, r := range text {
+		switch gsm7Septets[r] {
+		case 0:
+			l.NonGSM = append(l.NonGSM, r)
+		case 2:
+			l.Escaped = append(l.Escaped, r)
+		}
+	}
+
+	var sizes []int
+	if len(l.NonGSM) == 0 {
+		l.Alphabet = Alphabet7Bit
+		for _, r := range text {
+			sizes = append(sizes, gsm7Septets[r])
+		}
+		l.Segments, l.Units = segments(sizes, septetsSingle, septetsConcat)
+		l.PerSegment = septetsSingle
+		if l.Segments > 1 {
+			l.PerSegment = septetsConcat
+		}
+		return l
+	}
+
+	// Extension characters take a single unit in UCS-2
+	l.Alphabet, l.Escaped = AlphabetUCS2, nil
+	for _, r := range text {
+		sizes = append(sizes, utf16.RuneLen(r))
+	}
+	l.Segments, l.Units = segments(sizes, ucs2Single, ucs2Concat)
+	l.PerSegment = ucs2Single
+	if l.Segments > 1 {
+		l.PerSegment = ucs2Concat
+	}
+	return l
+}
+
+// segments counts the messages the characters of the given sizes take,
+// filling each part of a concatenated message up to concat units without
+// splitting a character. It also returns the total number of units.
+func segments(sizes []int, single, concat int) (count, total int) {
+	for _, n := range sizes {
+		total += n
+	}
+	if total == 0 {
+		return 1, 0
+	}
+	if total <= single {
+		return 1, total
+	}
+
+	count, used := 1, 0
+	for _, n := range sizes {
+		if used+n > concat {
+			count++
+			used = 0
+		}
+		used += n
+	}
+	return count, total
+}
@@ -0,0 +1,76 @@
+package pdu_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		alphabet   pdu.Alphabet
+		units      int
+		perSegment int
+		segments   int
+		escaped    []rune
+		nonGSM     []rune
+	}{
+		{name: "Empty", text: "", alphabet: pdu.Alphabet7Bit, perSegment: 160, segments: 1},
+		{name: "Single GSM message", text: strings.Repeat("a", 160), alphabet: pdu.Alphabet7Bit, units: 160, perSegment: 160, segments: 1},
+		{name: "Concatenated GSM message", text: strings.Repeat("a", 161), alphabet: pdu.Alphabet7Bit, units: 161, perSegment: 153, segments: 2},
+		{
+			name: "Extension characters count twice", text: "Price: 5€ [net]",
+			alphabet: pdu.Alphabet7Bit, units: 18, perSegment: 160, segments: 1,
+			escaped: []rune{'€', '[', ']'},
+		},
+		{
+			name: "Escape sequence is not split between parts", text: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152),
+			alphabet: pdu.Alphabet7Bit, units: 306, perSegment: 153, segments: 3,
+			escaped: []rune{'€'},
+		},
+		{
+			name: "En dash makes the text UCS-2", text: "20:00–21:00",
+			alphabet: pdu.AlphabetUCS2, units: 11, perSegment: 70, segments: 1,
+			nonGSM: []rune{'–'},
+		},
+		{
+			name: "Concatenated UCS-2 message", text: strings.Repeat("Ω", 60) + strings.Repeat("ж", 11),
+			alphabet: pdu.AlphabetUCS2, units: 71, perSegment: 67, segments: 2,
+			nonGSM: slices.Repeat([]rune{'ж'}, 11),
+		},
+		{
+			name: "Extension characters take one UCS-2 unit", text: "€ж",
+			alphabet: pdu.AlphabetUCS2, units: 2, perSegment: 70, segments: 1,
+			nonGSM: []rune{'ж'},
+		},
+		{
+			name: "Emoji take two units", text: "Hi 👋",
+			alphabet: pdu.AlphabetUCS2, units: 5, perSegment: 70, segments: 1,
+			nonGSM: []rune{'👋'},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := pdu.Measure(tt.text)
+
+			if l.Alphabet != tt.alphabet {
+				t.Errorf("expected alphabet %d, got %d", tt.alphabet, l.Alphabet)
+			}
+			if l.Units != tt.units || l.PerSegment != tt.perSegment || l.Segments != tt.segments {
+				t.Errorf("expected %d units, %d per segment and %d segments, got %d, %d and %d",
+					tt.units, tt.perSegment, tt.segments, l.Units, l.PerSegment, l.Segments)
+			}
+			if !slices.Equal(l.Escaped, tt.escaped) {
+				t.Errorf("expected escaped %q, got %q", tt.escaped, l.Escaped)
+			}
+			if !slices.Equal(l.NonGSM, tt.nonGSM) {
+				t.Errorf("expected non-GSM %q, got %q", tt.nonGSM, l.NonGSM)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package pdu implements encoding and decoding of SMS messages in PDU
+// (Protocol Data Unit) format as defined by 3GPP TS 23.040.
+//
+// Text mode (AT+CMGF=1) only covers plain GSM 7-bit messages. Anything beyond
+// that, such as 8-bit binary payloads, user data headers or port addressing,
+// requires the modem to be switched to PDU mode (AT+CMGF=0) and the TPDU to be
+// supplied as a hexadecimal string after the AT+CMGS prompt.
+//
+// # Usage Example
+//
+//	submit := pdu.Submit{
+//		Recipient: "+1234567890",
+//		DCS:       pdu.DCS8Bit,
+//		UDH:       pdu.PortAddressing16(2948, 9200),
+//		UserData:  payload,
+//	}
+//	tpdu, err := submit.Encode()
+//	if err != nil { return err }
+//
+//	// AT+CMGS takes the TPDU length, not including the SMSC field
+//	cmd := fmt.Sprintf("AT+CMGS=%d", len(tpdu))
+//	body := "00" + strings.ToUpper(hex.EncodeToString(tpdu))
+//
+// Incoming messages read in PDU mode are decoded with DecodeDeliver:
+//
+//	b, err := hex.DecodeString(pduLine)
+//	if err != nil { return err }
+//	msg, err := pdu.DecodeDeliver(b)
+package pdu
+
+import (
+	"errors"
+)
+
+// Data coding schemes (TP-DCS) supported by Submit.
+const (
+	// DCS7Bit marks the user data as GSM 7-bit text, see SplitGSM7.
+	DCS7Bit byte = 0x00
+	// DCS8Bit marks the user data as 8-bit binary octets.
+	DCS8Bit byte = 0x04
+	// DCSUCS2 marks the user data as UCS-2 text, see SplitUCS2.
+	DCSUCS2 byte = 0x08
+)
+
+// MaxUserDataLength is the maximum number of octets available for user data,
+// including the user data header, in a single SMS.
+const MaxUserDataLength = 140
+
+const (
+	// mtiSubmit is the message type indicator for SMS-SUBMIT
+	mtiSubmit byte = 0x01
+	// flagUDHI signals that the user data begins with a header
+	flagUDHI byte = 0x40
+)
+
+var (
+	// ErrInvalidAddress is returned when a phone number contains characters
+	// that cannot be represented as semi-octets.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrUserDataTooLong is returned when the user data including its header
+	// does not fit into a single SMS.
+	ErrUserDataTooLong = errors.New("user data too long")
+)
+
+// Submit describes an outgoing SMS-SUBMIT TPDU.
+type Submit struct {
+	// Recipient is the destination number. A leading "+" selects the
+	// international type of number.
+	Recipient string
+	// DCS is the TP-DCS octet describing how UserData is coded
+	DCS byte
+	// UDH holds the user data header information elements, without the
+	// leading length octet. It may be empty.
+	UDH []byte
+	// UserData is the already coded message payload. With DCS7Bit it holds
+	// one septet per octet, Encode packs them.
+	UserData []byte
+}
+
+// Encode returns the binary TPDU. The SMSC address is not part of the result;
+// callers sending via AT+CMGS prepend a zero-length SMSC field ("00") so the
+// modem uses the default service center stored on the SIM.
+func (s Submit) Encode() ([]byte, error) {
+	addr, err := encodeAddress(s.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	firstOctet := mtiSubmit
+	var header []byte
+	if len(s.UDH) > 0 {
+		firstOctet |= flagUDHI
+		header = append([]byte{byte(len(s.UDH))}, s.UDH...)
+	}
+
+	var ud []byte
+	var udl int
+	if s.DCS == DCS7Bit {
+		// The septets start at the septet boundary following the header,
+		// TP-UDL counts septets including the header
+		fill := (7 - len(header)*8%7) % 7
+		udl = (len(header)*8+fill)/7 + len(s.UserData)
+		if udl > septetsSingle {
+			return nil, ErrUserDataTooLong
+		}
+		ud = append(header, packSeptets(s.UserData, fill)...)
+	} else {
+		ud = append(header, s.UserData...)
+		udl = len(ud)
+		if udl > MaxUserDataLength {
+			return nil, ErrUserDataTooLong
+		}
+	}
+
+	tpdu := make([]byte, 0, 4+len(addr)+len(ud))
+	tpdu = append(tpdu, firstOctet, 0x00) // TP-MR is assigned by the modem
+	tpdu = append(tpdu, addr...)
+	tpdu = append(tpdu, 0x00, s.DCS, byte(udl)) // TP-PID, TP-DCS, TP-UDL
+	tpdu = append(tpdu, ud...)
+	return tpdu, nil
+}
+
+// encodeAddress encodes a phone number as a TP-DA field: the number of
+// digits, the type of address and the digits as swapped semi-octets.
+func encodeAddress(number string) ([]byte, error) {
+	toa := byte(0x81) // unknown type of number, ISDN numbering plan
+	if len(number) > 0 && number[0] == '+' {
+		toa = 0x91 // international
+		number = number[1:]
+	}
+	if number == "" {
+		return nil, ErrInvalidAddress
+	}
+
+	out := []byte{byte(len(number)), toa}
+	for i := 0; i < len(number); i += 2 {
+		lo, ok := semiOctet(number[i])
+		if !ok {
+			return nil, ErrInvalidAddress
+		}
+		hi := byte(0x0F) // filler for odd-length numbers
+		if i+1 < len(number) {
+			if hi, ok = semiOctet(number[i+1]); !ok {
+				return nil, ErrInvalidAddress
+			}
+		}
+		out = append(out, hi<<4|lo)
+	}
+	return out, nil
+}
+
+// semiOctet maps a dialling digit to its BCD value.
+func semiOctet(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c == '*':
+		return 0x0A, true
+	case c == '#':
+		return 0x0B, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,120 @@
+package pdu_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+func TestSubmitEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		submit   pdu.Submit
+		expected string
+	}{
+		{
+			name: "International recipient without header",
+			submit: pdu.Submit{
+				Recipient: "+1234567890",
+				DCS:       pdu.DCS8Bit,
+				UserData:  []byte("hi"),
+			},
+			expected: "01000A912143658709000402" + "6869",
+		},
+		{
+			name: "Odd length national recipient",
+			submit: pdu.Submit{
+				Recipient: "12345",
+				DCS:       pdu.DCS8Bit,
+				UserData:  []byte{0xFF},
+			},
+			expected: "010005812143F5" + "000401FF",
+		},
+		{
+			name: "WAP push port addressing",
+			submit: pdu.Submit{
+				Recipient: "+1234567890",
+				DCS:       pdu.DCS8Bit,
+				UDH:       pdu.PortAddressing16(2948, 9200),
+				UserData:  []byte{0x01, 0x06},
+			},
+			expected: "41000A912143658709000409" + "0605040B8423F0" + "0106",
+		},
+		{
+			name: "GSM 7-bit septets are packed",
+			submit: pdu.Submit{
+				Recipient: "+1234567890",
+				DCS:       pdu.DCS7Bit,
+				UserData:  []byte("hello"),
+			},
+			expected: "01000A912143658709000005" + "E8329BFD06",
+		},
+		{
+			name: "GSM 7-bit septets follow the header at a septet boundary",
+			submit: pdu.Submit{
+				Recipient: "+1234567890",
+				DCS:       pdu.DCS7Bit,
+				UDH:       pdu.Concat{Ref: 1, Total: 2, Seq: 1}.Encode(),
+				UserData:  []byte("hi"),
+			},
+			expected: "41000A912143658709000009" + "050003010201" + "D069",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpdu, err := tt.submit.Encode()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := strings.ToUpper(hex.EncodeToString(tpdu)); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSubmitEncodeErrors(t *testing.T) {
+	t.Run("ErrInvalidAddress on non-digit recipient", func(t *testing.T) {
+		_, err := pdu.Submit{Recipient: "+30abc"}.Encode()
+		if !errors.Is(err, pdu.ErrInvalidAddress) {
+			t.Errorf("expected ErrInvalidAddress, got: %v", err)
+		}
+	})
+
+	t.Run("ErrInvalidAddress on empty recipient", func(t *testing.T) {
+		_, err := pdu.Submit{Recipient: "+"}.Encode()
+		if !errors.Is(err, pdu.ErrInvalidAddress) {
+			t.Errorf("expected ErrInvalidAddress, got: %v", err)
+		}
+	})
+
+	t.Run("ErrUserDataTooLong when header pushes data over the limit", func(t *testing.T) {
+		_, err := pdu.Submit{
+			Recipient: "+1234567890",
+			DCS:       pdu.DCS8Bit,
+			UDH:       pdu.PortAddressing16(1, 2),
+			UserData:  bytes.Repeat([]byte{0}, pdu.MaxUserDataLength-6),
+		}.Encode()
+		if !errors.Is(err, pdu.ErrUserDataTooLong) {
+			t.Errorf("expected ErrUserDataTooLong, got: %v", err)
+		}
+	})
+
+	t.Run("ErrUserDataTooLong when header pushes septets over the limit", func(t *testing.T) {
+		// The 6 octet header takes 7 septets
+		_, err := pdu.Submit{
+			Recipient: "+1234567890",
+			DCS:       pdu.DCS7Bit,
+			UDH:       pdu.Concat{Ref: 1, Total: 2, Seq: 1}.Encode(),
+			UserData:  bytes.Repeat([]byte{'a'}, 154),
+		}.Encode()
+		if !errors.Is(err, pdu.ErrUserDataTooLong) {
+			t.Errorf("expected ErrUserDataTooLong, got: %v", err)
+		}
+	})
+}
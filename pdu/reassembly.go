@@ -0,0 +1,134 @@
+package pdu
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// Message is an incoming message joined from its parts.
+type Message struct {
+	// Sender is the originating address of the parts
+	Sender string
+	// Time is the service center time stamp of the part with the lowest
+	// sequence number present, which is not necessarily the first received
+	Time time.Time
+	// Alphabet is the character set of the parts
+	Alphabet Alphabet
+	// Text is the joined text for the 7-bit and UCS-2 alphabets
+	Text string
+	// UserData is the joined payload for the 8-bit alphabet
+	UserData []byte
+	// Parts is the number of parts the message was joined from
+	Parts int
+	// Partial is set if parts were still missing when the message was
+	// flushed by Expire. Missing parts are skipped.
+	Partial bool
+}
+
+// setKey identifies the parts of one concatenated message.
+type setKey struct {
+	sender string
+	ref    uint16
+	total  int
+}
+
+// partSet collects the parts of one concatenated message.
+type partSet struct {
+	parts    []*Deliver
+	received int
+	// first is when the first part arrived
+	first time.Time
+}
+
+// Reassembler joins the parts of concatenated messages, which may arrive
+// in any order and interleaved with other messages. Parts are matched by
+// sender and reference number. It is not safe for concurrent use.
+//
+//	r := pdu.NewReassembler(time.Hour)
+//	if msg, ok := r.Add(deliver, time.Now()); ok {
+//		// complete message
+//	}
+//	for _, msg := range r.Expire(time.Now()) {
+//		// incomplete message, msg.Partial is set
+//	}
+type Reassembler struct {
+	timeout time.Duration
+	sets    map[setKey]*partSet
+}
+
+// NewReassembler returns a Reassembler that gives up waiting for missing
+// parts timeout after the first part of a message arrived.
+func NewReassembler(timeout time.Duration) *Reassembler {
+	return &Reassembler{timeout: timeout, sets: make(map[setKey]*partSet)}
+}
+
+// Add adds a received message at time now. It returns the joined message
+// once all of its parts arrived. Messages that are not concatenated are
+// returned right away. A part received twice replaces the earlier copy.
+func (r *Reassembler) Add(d Deliver, now time.Time) (Message, bool) {
+	concat, ok := d.Concat()
+	if !ok || concat.Seq < 1 || concat.Seq > concat.Total {
+		return join([]*Deliver{&d}, false), true
+	}
+
+	key := setKey{sender: d.Sender, ref: concat.Ref, total: concat.Total}
+	set, ok := r.sets[key]
+	if !ok {
+		set = &partSet{parts: make([]*Deliver, concat.Total), first: now}
+		r.sets[key] = set
+	}
+	if set.parts[concat.Seq-1] == nil {
+		set.received++
+	}
+	set.parts[concat.Seq-1] = &d
+
+	if set.received < concat.Total {
+		return Message{}, false
+	}
+	delete(r.sets, key)
+	return join(set.parts, false), true
+}
+
+// Expire flushes the messages whose first part arrived at least the
+// timeout before now, joined from the parts received so far.
+func (r *Reassembler) Expire(now time.Time) []Message {
+	var expired []Message
+	for key, set := range r.sets {
+		if now.Sub(set.first) >= r.timeout {
+			expired = append(expired, join(set.parts, true))
+			delete(r.sets, key)
+		}
+	}
+	return expired
+}
+
+// Pending returns the number of messages waiting for parts.
+func (r *Reassembler) Pending() int {
+	return len(r.sets)
+}
+
+// join combines the parts in order, skipping missing ones.
+func join(parts []*Deliver, partial bool) Message {
+	var (
+		msg  = Message{Partial: partial}
+		text strings.Builder
+		data bytes.Buffer
+	)
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		if msg.Parts == 0 {
+			msg.Sender, msg.Time, msg.Alphabet = p.Sender, p.Time, p.Alphabet
+		}
+		msg.Parts++
+		text.WriteString(p.Text)
+		data.Write(p.UserData)
+	}
+	msg.Text = text.String()
+	if data.Len() > 0 {
+		msg.UserData = data.Bytes()
+	}
+	return msg
+}
@@ -0,0 +1,90 @@
+package pdu_test
+
+import (
+	"testing"
+	"time"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+// part returns a part of a concatenated text message.
+func part(sender string, ref, total, seq byte, text string) pdu.Deliver {
+	return pdu.Deliver{
+		Sender: sender,
+		UDH:    []byte{pdu.IEIConcat8, 0x03, ref, total, seq},
+		Text:   text,
+	}
+}
+
+func TestReassembler(t *testing.T) {
+	start := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Joins parts received out of order", func(t *testing.T) {
+		r := pdu.NewReassembler(time.Hour)
+
+		if _, ok := r.Add(part("+306912345678", 7, 3, 3, " 4"), start); ok {
+			t.Fatal("expected message to be incomplete")
+		}
+		// Another message interleaved with the same reference number
+		if _, ok := r.Add(part("+306987654321", 7, 2, 1, "Other"), start); ok {
+			t.Fatal("expected message to be incomplete")
+		}
+		if _, ok := r.Add(part("+306912345678", 7, 3, 1, "Pump"), start); ok {
+			t.Fatal("expected message to be incomplete")
+		}
+		msg, ok := r.Add(part("+306912345678", 7, 3, 2, " failure at pump"), start)
+		if !ok {
+			t.Fatal("expected message to be complete")
+		}
+
+		expected := pdu.Message{Sender: "+306912345678", Text: "Pump failure at pump 4", Parts: 3}
+		if msg.Sender != expected.Sender || msg.Text != expected.Text || msg.Parts != expected.Parts || msg.Partial {
+			t.Errorf("expected %+v, got %+v", expected, msg)
+		}
+		if got := r.Pending(); got != 1 {
+			t.Errorf("expected 1 pending message, got %d", got)
+		}
+	})
+
+	t.Run("Returns single messages right away", func(t *testing.T) {
+		r := pdu.NewReassembler(time.Hour)
+
+		msg, ok := r.Add(pdu.Deliver{Sender: "InfoSMS", Text: "Hi"}, start)
+		if !ok || msg.Text != "Hi" || msg.Parts != 1 {
+			t.Errorf("expected single message, got %+v (%v)", msg, ok)
+		}
+	})
+
+	t.Run("Ignores duplicate parts", func(t *testing.T) {
+		r := pdu.NewReassembler(time.Hour)
+
+		r.Add(part("+306912345678", 1, 2, 1, "one"), start)
+		if _, ok := r.Add(part("+306912345678", 1, 2, 1, "one"), start); ok {
+			t.Fatal("expected duplicate not to complete the message")
+		}
+		if msg, ok := r.Add(part("+306912345678", 1, 2, 2, " two"), start); !ok || msg.Text != "one two" {
+			t.Errorf("expected joined message, got %+v (%v)", msg, ok)
+		}
+	})
+
+	t.Run("Flushes incomplete messages after the timeout", func(t *testing.T) {
+		r := pdu.NewReassembler(time.Hour)
+
+		r.Add(part("+306912345678", 9, 3, 1, "first"), start)
+		r.Add(part("+306912345678", 9, 3, 3, " third"), start.Add(30*time.Minute))
+
+		if expired := r.Expire(start.Add(59 * time.Minute)); len(expired) != 0 {
+			t.Fatalf("expected nothing to expire yet, got %+v", expired)
+		}
+		expired := r.Expire(start.Add(time.Hour))
+		if len(expired) != 1 {
+			t.Fatalf("expected 1 expired message, got %+v", expired)
+		}
+		if msg := expired[0]; msg.Text != "first third" || msg.Parts != 2 || !msg.Partial {
+			t.Errorf("expected partial message of 2 parts, got %+v", msg)
+		}
+		if got := r.Pending(); got != 0 {
+			t.Errorf("expected no pending messages, got %d", got)
+		}
+	})
+}
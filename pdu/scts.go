@@ -0,0 +1,58 @@
+package pdu
+
+import (
+	"fmt"
+	"time"
+)
+
+// sctsLength is the length of a TP-SCTS field in octets.
+const sctsLength = 7
+
+// DecodeSCTS decodes a service center time stamp (TP-SCTS): year, month,
+// day, hour, minute and second as swapped BCD semi-octets, followed by the
+// time zone in quarter hours.
+//
+// The sign of the time zone is bit 3 of the zone octet, which is the high
+// bit of its tens digit after swapping, not the high bit of the octet.
+// Two-digit years are taken as 20yy.
+func DecodeSCTS(b []byte) (time.Time, error) {
+	if len(b) != sctsLength {
+		return time.Time{}, fmt.Errorf("%w: time stamp of %d octets", ErrMalformedPDU, len(b))
+	}
+
+	var fields [6]int
+	for i := range fields {
+		v, ok := swappedBCD(b[i])
+		if !ok {
+			return time.Time{}, fmt.Errorf("%w: invalid time stamp %X", ErrMalformedPDU, b)
+		}
+		fields[i] = v
+	}
+	year, month, day, hour, minute, second := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, fmt.Errorf("%w: invalid time stamp %X", ErrMalformedPDU, b)
+	}
+
+	tz := b[6]
+	tens, units := int(tz&0x07), int(tz>>4)
+	if units > 9 {
+		return time.Time{}, fmt.Errorf("%w: invalid time zone %02X", ErrMalformedPDU, tz)
+	}
+	quarters := tens*10 + units
+	if tz&0x08 != 0 {
+		quarters = -quarters
+	}
+
+	zone := time.FixedZone("", quarters*15*60)
+	return time.Date(2000+year, time.Month(month), day, hour, minute, second, 0, zone), nil
+}
+
+// swappedBCD decodes an octet holding two decimal digits, the first one in
+// the low semi-octet.
+func swappedBCD(b byte) (int, bool) {
+	lo, hi := b&0x0F, b>>4
+	if lo > 9 || hi > 9 {
+		return 0, false
+	}
+	return int(lo)*10 + int(hi), true
+}
@@ -0,0 +1,35 @@
+package pdu
+
+import "unicode/utf16"
+
+// SplitUCS2 encodes text as UCS-2 user data, split into the parts of a
+// concatenated message if it does not fit into a single one. Each part
+// leaves room for an 8-bit concatenation header, see Concat.Encode, and
+// surrogate pairs are never split between parts.
+func SplitUCS2(text string) [][]byte {
+	units := utf16.Encode([]rune(text))
+	if len(units) <= ucs2Single {
+		return [][]byte{encodeUnits(units)}
+	}
+
+	var parts [][]byte
+	for len(units) > 0 {
+		n := min(ucs2Concat, len(units))
+		if n < len(units) && utf16.IsSurrogate(rune(units[n-1])) && units[n-1] < 0xDC00 {
+			// Keep the high surrogate with its low surrogate
+			n--
+		}
+		parts = append(parts, encodeUnits(units[:n]))
+		units = units[n:]
+	}
+	return parts
+}
+
+// encodeUnits returns UTF-16 code units in big endian byte order.
+func encodeUnits(units []uint16) []byte {
+	b := make([]byte, 0, 2*len(units))
+	for _, u := range units {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return b
+}
@@ -0,0 +1,55 @@
+package pdu_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"i4.energy/across/smsgw/pdu"
+)
+
+func TestSplitUCS2(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		sizes []int
+	}{
+		{name: "Single message", text: strings.Repeat("ж", 70), sizes: []int{140}},
+		{name: "Concatenated message", text: strings.Repeat("ж", 71), sizes: []int{134, 8}},
+		{name: "Surrogate pair moves to the next part", text: strings.Repeat("ж", 66) + "👋" + "жжж", sizes: []int{132, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := pdu.SplitUCS2(tt.text)
+
+			var sizes []int
+			var units []uint16
+			for _, part := range parts {
+				sizes = append(sizes, len(part))
+				for i := 0; i+1 < len(part); i += 2 {
+					units = append(units, uint16(part[i])<<8|uint16(part[i+1]))
+				}
+			}
+			if !slices.Equal(sizes, tt.sizes) {
+				t.Errorf("expected parts of %v octets, got %v", tt.sizes, sizes)
+			}
+			if got := string(utf16.Decode(units)); got != tt.text {
+				t.Errorf("expected parts to join to %q, got %q", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestConcatEncode(t *testing.T) {
+	for _, c := range []pdu.Concat{
+		{Ref: 0x42, Total: 3, Seq: 2},
+		{Ref: 0x1234, Total: 2, Seq: 1},
+	} {
+		got, ok := pdu.ParseConcat(c.Encode())
+		if !ok || got != c {
+			t.Errorf("expected %+v to parse back, got %+v (ok %t)", c, got, ok)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package pdu
+
+// Information element identifiers used in the user data header.
+const (
+	// IEIPortAddressing16 addresses an application port using 16-bit port
+	// numbers, as used by WAP push (destination port 2948).
+	IEIPortAddressing16 byte = 0x05
+)
+
+// PortAddressing16 returns a user data header information element that
+// addresses the given 16-bit destination and source application ports.
+func PortAddressing16(dst, src uint16) []byte {
+	return []byte{
+		IEIPortAddressing16, 0x04,
+		byte(dst >> 8), byte(dst),
+		byte(src >> 8), byte(src),
+	}
+}
+
+// Information element identifiers of concatenated messages.
+const (
+	// IEIConcat8 marks a part of a concatenated message with an 8-bit
+	// reference number
+	IEIConcat8 byte = 0x00
+	// IEIConcat16 marks a part of a concatenated message with a 16-bit
+	// reference number
+	IEIConcat16 byte = 0x08
+)
+
+// Concat identifies a part of a concatenated message. The parts of a
+// message share the sender and the reference number.
+type Concat struct {
+	// Ref is the reference number of the message
+	Ref uint16
+	// Total is the number of parts of the message
+	Total int
+	// Seq is the number of this part, starting at 1
+	Seq int
+}
+
+// Encode returns the concatenation information element for the header of
+// this part. References above 255 use the 16-bit form. Total and Seq are
+// coded in an octet each, callers must not split a message into more than
+// 255 parts.
+func (c Concat) Encode() []byte {
+	if c.Ref > 0xFF {
+		return []byte{IEIConcat16, 0x04, byte(c.Ref >> 8), byte(c.Ref), byte(c.Total), byte(c.Seq)}
+	}
+	return []byte{IEIConcat8, 0x03, byte(c.Ref), byte(c.Total), byte(c.Seq)}
+}
+
+// ParseConcat looks up the concatenation information element in a user
+// data header, given without its length octet.
+func ParseConcat(udh []byte) (Concat, bool) {
+	for len(udh) >= 2 {
+		iei, length := udh[0], int(udh[1])
+		if 2+length > len(udh) {
+			break
+		}
+		data := udh[2 : 2+length]
+		udh = udh[2+length:]
+
+		switch {
+		case iei == IEIConcat8 && length == 3:
+			return Concat{Ref: uint16(data[0]), Total: int(data[1]), Seq: int(data[2])}, data[1] > 0
+		case iei == IEIConcat16 && length == 4:
+			ref := uint16(data[0])<<8 | uint16(data[1])
+			return Concat{Ref: ref, Total: int(data[2]), Seq: int(data[3])}, data[2] > 0
+		}
+	}
+	return Concat{}, false
+}